@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type bumpRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Path      string    `json:"path"`
+	Latest    string    `json:"latest"`
+	Version   string    `json:"version"`
+	Bump      string    `json:"bump"`
+}
+
+// appendBumpLog records a computed bump as a JSON line. Failures only warn so
+// that the audit trail never breaks a release.
+func appendBumpLog(opts Options, path string, latestTag, nextVersion SemVer) {
+	record := bumpRecord{
+		Timestamp: now().UTC(),
+		Path:      path,
+		Latest:    FormatTag(latestTag, opts),
+		Version:   FormatTag(nextVersion, opts),
+		Bump:      BumpKind(latestTag, nextVersion),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		slog.Warn("failed to encode bump log record", "error", err)
+		return
+	}
+
+	f, err := os.OpenFile(opts.BumpLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		slog.Warn("failed to open bump log", "file", opts.BumpLog, "error", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		slog.Warn("failed to write bump log", "file", opts.BumpLog, "error", err)
+	}
+}
+
+// writeBumpNote attaches the bump rationale to the commit of the tag just
+// created as a git note under refs/notes/semver. Like the bump log, failures
+// only warn.
+func writeBumpNote(opts Options, path string, latestTag, nextVersion SemVer) {
+	input := func(n int) string {
+		if n == -1 {
+			return "latest"
+		}
+		return strconv.Itoa(n)
+	}
+	note := fmt.Sprintf("%s -> %s (%s)\ninputs: major=%s minor=%s",
+		FormatTag(latestTag, opts), FormatTag(nextVersion, opts), BumpKind(latestTag, nextVersion), input(opts.Major), input(opts.Minor))
+	if commits, err := countCommitsSince(path, latestTag.Raw); err == nil {
+		note += fmt.Sprintf("\ncommits: %d", commits)
+	}
+
+	cmd := gitCommand(path, "notes", "--ref", "semver", "add", "--force", "--message", note, "refs/tags/"+FormatTag(nextVersion, opts)+"^{commit}")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		slog.Warn("failed to write git note", "path", path, "error", err, "output", strings.TrimSpace(string(output)))
+	}
+}
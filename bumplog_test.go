@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBumpLog(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC) }
+	dir := newRepo(t, "v1.2.3")
+	logFile := filepath.Join(t.TempDir(), "bumps.jsonl")
+
+	for _, minor := range []string{"2", "3"} {
+		got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", minor, "--bump-log", logFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(got, "timestamp") {
+			t.Errorf("bump log leaked to stdout: %q", got)
+		}
+	}
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"timestamp":"2024-03-15T12:00:00Z","path":"` + dir + `","latest":"v1.2.3","version":"v1.2.4","bump":"patch"}` + "\n" +
+		`{"timestamp":"2024-03-15T12:00:00Z","path":"` + dir + `","latest":"v1.2.3","version":"v1.3.0","bump":"minor"}` + "\n"
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+}
+
+func TestBumpLogFailureOnlyWarns(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	logs := captureLog(t)
+	got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--bump-log", filepath.Join(t.TempDir(), "missing", "bumps.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4" {
+		t.Errorf("got %q, want v1.2.4", got)
+	}
+	if !strings.Contains(logs.String(), "failed to open bump log") {
+		t.Errorf("missing warning: %s", logs)
+	}
+}
+
+func TestWriteNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		tag  string
+		want string
+	}{
+		{"patch", []string{"--major", "1", "--minor", "2"}, "v1.2.4", "v1.2.3 -> v1.2.4 (patch)\ninputs: major=1 minor=2\ncommits: 2"},
+		{"inferred", []string{"--minor", "3"}, "v1.3.0", "v1.2.3 -> v1.3.0 (minor)\ninputs: major=latest minor=3\ncommits: 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.3")
+			commit(t, dir, "fix")
+			commit(t, dir, "feature")
+			if _, err := runArgs(t, append([]string{"--path", dir, "--create-tag", "--write-notes"}, tt.args...)...); err != nil {
+				t.Fatal(err)
+			}
+			commit(t, dir, "after release")
+			if got := runGit(t, dir, "notes", "--ref", "semver", "show", tt.tag); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := runGit(t, dir, "notes", "--ref", "semver", "list"); !strings.HasSuffix(got, " "+runGit(t, dir, "rev-parse", tt.tag+"^{commit}")) {
+				t.Errorf("note annotates %q, want the commit of %s", got, tt.tag)
+			}
+		})
+	}
+}
+
+func TestWriteNotesRequiresCreateTag(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	_, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--write-notes")
+	if err == nil || err.Error() != "--write-notes requires --create-tag or --commit" {
+		t.Fatalf("got %v, want --write-notes requires --create-tag or --commit", err)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// now is the clock used for date-based versions
+var now = time.Now
+
+// calculateCalVer computes a YYYY.MM.SEQ version for the month of t. SEQ
+// continues from the latest tag within the same month and restarts at 0 in a
+// new month; a latest tag from a later month is rejected so versions never go
+// backwards.
+func calculateCalVer(latestTag SemVer, t time.Time) (SemVer, error) {
+	year, month := t.Year(), int(t.Month())
+
+	switch {
+	case latestTag.Major == year && latestTag.Minor == month:
+		return SemVer{Major: year, Minor: month, Patch: latestTag.Patch + 1}, nil
+	case latestTag.Major < year || (latestTag.Major == year && latestTag.Minor < month):
+		return SemVer{Major: year, Minor: month, Patch: 0}, nil
+	}
+	return SemVer{}, fmt.Errorf("latest tag %s is newer than the current month %d.%d", latestTag, year, month)
+}
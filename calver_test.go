@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalculateCalVer(t *testing.T) {
+	march := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		latest  SemVer
+		want    string
+		wantErr bool
+	}{
+		{"no tags", Zero(), "v2024.3.0", false},
+		{"same month", SemVer{Major: 2024, Minor: 3, Patch: 4}, "v2024.3.5", false},
+		{"earlier month", SemVer{Major: 2024, Minor: 2, Patch: 9}, "v2024.3.0", false},
+		{"earlier year", SemVer{Major: 2023, Minor: 12, Patch: 1}, "v2024.3.0", false},
+		{"later month", SemVer{Major: 2024, Minor: 4, Patch: 0}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateCalVer(tt.latest, march)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalVer(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC) }
+
+	dir := newRepo(t, "v2024.2.3", "v2024.3.0")
+	got, err := runArgs(t, "--path", dir, "--calver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(got) != "v2024.3.1" {
+		t.Errorf("got %q, want v2024.3.1", got)
+	}
+}
+
+func TestYearScoped(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name    string
+		tags    []string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"patch within the year", []string{"v2023.4.2", "v2024.1.3"}, nil, "v2024.1.4", false},
+		{"minor within the year", []string{"v2023.4.2", "v2024.1.3"}, []string{"--minor", "2"}, "v2024.2.0", false},
+		{"first of the year", []string{"v2023.4.2"}, nil, "v2024.0.0", false},
+		{"later years ignored", []string{"v2024.1.0", "v2025.0.0"}, nil, "v2024.1.1", false},
+		{"with --major", []string{"v2024.1.0"}, []string{"--major", "2024"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			got, err := runArgs(t, append([]string{"--path", dir, "--year-scoped"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMajorNotBefore(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 12, 0, 0, 0, time.Local) }
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		date         string
+		major, minor string
+		want         string
+		wantErr      string
+	}{
+		{"2024-06-01", "2", "0", "", "major bump to v2.0.0 is not allowed before 2024-06-01"},
+		{"2024-06-01", "1", "3", "v1.3.0", ""},
+		{"2024-03-15", "2", "0", "v2.0.0", ""},
+		{"2024-03-01", "2", "0", "v2.0.0", ""},
+		{"June 1st", "2", "0", "", "invalid --major-not-before"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date+"/"+tt.major+"."+tt.minor, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--major-not-before", tt.date, "--major", tt.major, "--minor", tt.minor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	released, err := tagCommitTime(dir, "v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { now = time.Now })
+	tests := []struct {
+		elapsed  time.Duration
+		interval string
+		wantErr  string
+	}{
+		{2 * time.Hour, "24h", "latest tag v1.2.3 is only 2h0m0s old; --min-interval requires 24h0m0s between releases"},
+		{25 * time.Hour, "24h", ""},
+		{24 * time.Hour, "24h", ""},
+		{time.Minute, "0s", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.elapsed.String()+"/"+tt.interval, func(t *testing.T) {
+			now = func() time.Time { return released.Add(tt.elapsed) }
+			got, err := runArgs(t, "--path", dir, "--min-interval", tt.interval, "--major", "1", "--minor", "2")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "v1.2.4" {
+				t.Errorf("got %q, want v1.2.4", got)
+			}
+		})
+	}
+
+	// A repository without tags has no release to wait for
+	untagged := newRepo(t)
+	commit(t, untagged, "initial")
+	if _, err := runArgs(t, "--path", untagged, "--min-interval", "24h", "--major", "0", "--minor", "1"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// applyChannel sets the prerelease of a computed version for a release
+// channel: stable keeps it clean, beta numbers it after the existing
+// -beta.N tags of the same version and nightly stamps the date
+func applyChannel(path string, next SemVer, opts Options) (SemVer, error) {
+	switch opts.Channel {
+	case "beta":
+		return nextPrerelease(path, next, "beta", opts)
+	case "nightly":
+		next.Prerelease = "nightly." + now().UTC().Format("20060102")
+	}
+	return next, nil
+}
+
+// nextPrerelease numbers a prerelease track after its existing tags of the
+// same version, starting at <id>.1
+func nextPrerelease(path string, next SemVer, id string, opts Options) (SemVer, error) {
+	tags, err := getSemverTags(path, opts)
+	if err != nil {
+		return SemVer{}, err
+	}
+	next.Prerelease = fmt.Sprintf("%s.%d", id, highestPrerelease(tags, next, id)+1)
+	return next, nil
+}
+
+// highestPrerelease returns the highest N of the <id>.N prereleases of
+// version among tags, or 0. Tags are compared as parsed versions, so the
+// prefix case, suffix and build metadata do not matter.
+func highestPrerelease(tags []SemVer, version SemVer, id string) int {
+	version.Prerelease = ""
+	highest := 0
+	for _, tag := range tags {
+		if tag.Raw == "" || tag.Prerelease == "" {
+			continue
+		}
+		number, ok := strings.CutPrefix(tag.Prerelease, id+".")
+		if !ok {
+			continue
+		}
+		tag.Prerelease = ""
+		if !tag.Equal(version) {
+			continue
+		}
+		if n, err := strconv.Atoi(number); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
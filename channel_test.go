@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHighestPrerelease(t *testing.T) {
+	tags, err := parseSemverTags([]string{"v1.2.4-beta.9", "V1.2.4-beta.10", "v1.2.4-beta.x", "v1.3.0-beta.20", "v1.2.4-rc.30", "v1.2.3"}, Options{PrefixCase: "lower", Prereleases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		version SemVer
+		id      string
+		want    int
+	}{
+		{SemVer{Major: 1, Minor: 2, Patch: 4}, "beta", 10},
+		{SemVer{Major: 1, Minor: 2, Patch: 4}, "rc", 30},
+		{SemVer{Major: 1, Minor: 3}, "beta", 20},
+		{SemVer{Major: 1, Minor: 4}, "beta", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.String()+"/"+tt.id, func(t *testing.T) {
+			if got := highestPrerelease(tags, tt.version, tt.id); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannel(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC) }
+	dir := newRepo(t, "v1.2.3", "v1.2.4-beta.1", "v1.2.4-beta.2")
+	tests := []struct {
+		channel, minor string
+		want           string
+	}{
+		{"stable", "2", "v1.2.4"},
+		{"beta", "2", "v1.2.4-beta.3"},
+		{"beta", "3", "v1.3.0-beta.1"},
+		{"nightly", "2", "v1.2.4-nightly.20240315"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channel+"/"+tt.minor, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", tt.minor, "--channel", tt.channel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if err := validateOptions(parseArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--channel", "alpha")); err == nil {
+		t.Error("expected an unknown channel to be rejected")
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{"first", []string{"v1.2.3"}, "rc", "v1.3.0-rc.1", false},
+		{"numeric order", []string{"v1.2.3", "v1.3.0-rc.9", "v1.3.0-rc.10"}, "rc", "v1.3.0-rc.11", false},
+		{"other track", []string{"v1.2.3", "v1.3.0-rc.2"}, "beta", "v1.3.0-beta.1", false},
+		{"not an identifier", []string{"v1.2.3"}, "rc.1", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "3", "--next-prerelease", tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
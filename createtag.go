@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// createNextTag tags HEAD with the next version. When another process created
+// the same tag first, --resolve-conflicts re-reads the tags and retries with
+// the recomputed version, up to the given number of times; each retry goes
+// through the pre-bump guards again. It returns the versions actually tagged.
+func createNextTag(latestTag, nextVersion SemVer, opts Options) (SemVer, SemVer, error) {
+	for retry := 0; ; retry++ {
+		nextTag := FormatTag(nextVersion, opts)
+		err := createTag(opts.Path, nextTag)
+		if err == nil {
+			return latestTag, nextVersion, nil
+		}
+		if _, revErr := resolveRev(opts.Path, "refs/tags/"+nextTag); revErr != nil {
+			// The tag does not exist, so this is not a conflict
+			return SemVer{}, SemVer{}, err
+		}
+		if retry == opts.ResolveConflicts {
+			if retry == 0 {
+				return SemVer{}, SemVer{}, fmt.Errorf("%w; it was created concurrently, retry with --resolve-conflicts", err)
+			}
+			return SemVer{}, SemVer{}, fmt.Errorf("%w; still conflicting after %d retries", err, retry)
+		}
+
+		slog.Info("tag was created concurrently; recomputing", "tag", nextTag, "retry", retry+1)
+		if latestTag, nextVersion, err = computeNextVersion(opts.Path, opts); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+		if err := beforeBump(latestTag, nextVersion, opts); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// concurrentTagger writes a --pre-bump-script that tags the proposed version
+// in dir, as a concurrent pipeline would, during its first n runs
+func concurrentTagger(t *testing.T, dir string, n int) string {
+	t.Helper()
+	scripts := t.TempDir()
+	script := filepath.Join(scripts, "race.sh")
+	count := filepath.Join(scripts, "count")
+	body := fmt.Sprintf(`#!/bin/sh
+runs=$(cat '%[1]s' 2>/dev/null || echo 0)
+echo $((runs + 1)) > '%[1]s'
+if [ "$runs" -lt %[2]d ]; then git -C '%[3]s' tag "$1"; fi
+`, count, n, dir)
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestCreateTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		concurrent int
+		args       []string
+		want       string
+		wantTags   string
+		wantErr    string
+	}{
+		{name: "tags HEAD", want: "v1.2.4", wantTags: "v1.2.4"},
+		{
+			name:       "conflict",
+			concurrent: 1,
+			wantTags:   "v1.2.4",
+			wantErr:    "failed to create tag v1.2.4: * already exists; it was created concurrently, retry with --resolve-conflicts",
+		},
+		{
+			name:       "retry at the next patch",
+			concurrent: 1,
+			args:       []string{"--resolve-conflicts", "3"},
+			want:       "v1.2.5",
+			wantTags:   "v1.2.4\nv1.2.5",
+		},
+		{
+			name:       "retries exhausted",
+			concurrent: 5,
+			args:       []string{"--resolve-conflicts", "2"},
+			wantTags:   "v1.2.4\nv1.2.5\nv1.2.6",
+			wantErr:    "failed to create tag v1.2.6: * already exists; still conflicting after 2 retries",
+		},
+		{name: "validate only", args: []string{"--validate-only"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.3")
+			commit(t, dir, "change")
+			args := []string{"--path", dir, "--major", "1", "--minor", "2", "--create-tag"}
+			if tt.concurrent > 0 {
+				args = append(args, "--pre-bump-script", concurrentTagger(t, dir, tt.concurrent))
+			}
+			logs := captureLog(t)
+			got, err := runArgs(t, append(args, tt.args...)...)
+			if tt.wantErr != "" {
+				// The middle of the message is git's own error
+				prefix, suffix, _ := strings.Cut(tt.wantErr, " * ")
+				if err == nil || !strings.HasPrefix(err.Error(), prefix) || !strings.HasSuffix(err.Error(), suffix) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tags := runGit(t, dir, "tag", "--points-at", "HEAD"); tags != tt.wantTags {
+				t.Errorf("HEAD tags = %q, want %q", tags, tt.wantTags)
+			}
+			if retried := strings.Contains(logs.String(), "tag was created concurrently; recomputing"); retried != slices.Contains(tt.args, "--resolve-conflicts") {
+				t.Errorf("logged a retry = %v in %q", retried, logs)
+			}
+		})
+	}
+}
+
+func TestCreateTagValidation(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tags := writeFile(t, "tags.txt", "v1.2.3\n")
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--path", dir, "--major", "1", "--resolve-conflicts", "2"}, "--resolve-conflicts requires --create-tag"},
+		{[]string{"--path", dir, "--major", "1", "--create-tag", "--resolve-conflicts", "-1"}, "invalid --resolve-conflicts -1: must not be negative"},
+		{[]string{"--path", filepath.Join(dir, "*"), "--major", "1", "--create-tag"}, "--create-tag requires a single local repository and cannot be combined with --watch, --serve or other modes"},
+		{[]string{"--tags-file", tags, "--major", "1", "--create-tag"}, "--create-tag requires a single local repository and cannot be combined with --watch, --serve or other modes"},
+		{[]string{"--path", dir, "--lint", "--create-tag"}, "--create-tag requires a single local repository and cannot be combined with --watch, --serve or other modes"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[2:], " "), func(t *testing.T) {
+			_, err := runArgs(t, tt.args...)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Describe represents the output of git describe relative to a semver tag
+type Describe struct {
+	Base     SemVer
+	Distance int
+	SHA      string
+}
+
+// Format renders the description with the base tag formatted like every other tag
+func (d Describe) Format(opts Options) string {
+	return fmt.Sprintf("base=%s distance=%d sha=%s", FormatTag(d.Base, opts), d.Distance, d.SHA)
+}
+
+// DevVersion returns the next patch with a dev prerelease, or the base itself on an exact tag
+func (d Describe) DevVersion(opts Options) string {
+	if d.Distance == 0 {
+		return FormatTag(d.Base, opts)
+	}
+	next := d.Base.BumpPatch()
+	if d.Base.Prerelease == "" {
+		// The patch of two- and four-component versions is another component
+		next = applyComponents(d.Base, next, d.Base.Components)
+	}
+	next.Prerelease = fmt.Sprintf("dev.%d", d.Distance)
+	return FormatTag(next, opts)
+}
+
+func runDescribe(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+
+	d, err := describeHead(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.DevVersion {
+		printResult(out, d.DevVersion(opts), opts)
+	} else {
+		printResult(out, d.Format(opts), opts)
+	}
+	return nil
+}
+
+// describeHead describes HEAD relative to the nearest semver tag, skipping
+// any other tags in between like latestTagByTopology
+func describeHead(path string, opts Options) (Describe, error) {
+	args := []string{"describe", "--tags"}
+	for {
+		cmd := gitCommand(path, args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return Describe{}, fmt.Errorf("failed to describe HEAD: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		d, err := parseDescribe(strings.TrimSpace(string(output)), opts)
+		if !errors.Is(err, errNotSemverTag) {
+			return d, err
+		}
+		name := strings.TrimSpace(string(output))
+		if matches := describeSuffixRegex.FindStringSubmatch(name); matches != nil {
+			name = matches[1]
+		}
+		args = append(args, "--exclude", name)
+	}
+}
+
+// errNotSemverTag is returned for git describe output naming another kind of tag
+var errNotSemverTag = errors.New("does not reference a semver tag")
+
+// describeSuffixRegex matches the -<distance>-g<sha> that git describe appends
+// when HEAD is past the tag
+var describeSuffixRegex = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]+)$`)
+
+// parseDescribe reads git describe output; the tag is parsed like any other
+// tag, so it follows --prefix, --components and the other format options
+func parseDescribe(output string, opts Options) (Describe, error) {
+	name, d := output, Describe{}
+	if matches := describeSuffixRegex.FindStringSubmatch(output); matches != nil {
+		name = matches[1]
+		d.Distance, _ = strconv.Atoi(matches[2])
+		d.SHA = matches[3]
+	}
+
+	tags, err := parseSemverTags([]string{name}, opts)
+	if err != nil {
+		return Describe{}, err
+	}
+	if tags[0].Raw == "" {
+		return Describe{}, fmt.Errorf("git describe output %q %w", output, errNotSemverTag)
+	}
+	d.Base = tags[0]
+	return d, nil
+}
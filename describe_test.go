@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDescribe(t *testing.T) {
+	tests := []struct {
+		output   string
+		base     string
+		distance int
+		sha      string
+		wantErr  bool
+	}{
+		{output: "v1.2.3", base: "v1.2.3"},
+		{output: "v1.2.3-4-gabc1234", base: "v1.2.3", distance: 4, sha: "abc1234"},
+		{output: "v1.3.0-rc.1-2-g0f4795d", base: "v1.3.0-rc.1", distance: 2, sha: "0f4795d"},
+		{output: "release-7", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			d, err := parseDescribe(tt.output, Options{Prereleases: true})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Base.String() != tt.base || d.Distance != tt.distance || d.SHA != tt.sha {
+				t.Errorf("got base=%s distance=%d sha=%s", d.Base, d.Distance, d.SHA)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"on tag", nil, "base=v1.2.3 distance=0 sha="},
+		{"dev version on tag", []string{"--dev-version"}, "v1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--describe"}, tt.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	commit(t, dir, "work")
+	commit(t, dir, "more work")
+	got, err := runArgs(t, "--path", dir, "--describe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "base=v1.2.3 distance=2 sha=") {
+		t.Errorf("got %q", got)
+	}
+	got, err = runArgs(t, "--path", dir, "--describe", "--dev-version", "--prefix-case", "upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "V1.2.4-dev.2" {
+		t.Errorf("got %q, want V1.2.4-dev.2", got)
+	}
+}
+
+func TestDescribeSkipsOtherTags(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	commit(t, dir, "nightly build")
+	runGit(t, dir, "tag", "nightly")
+	commit(t, dir, "work")
+	runGit(t, dir, "tag", "v1.2.x-broken")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "base=v1.2.3 distance=2 sha="},
+		{[]string{"--dev-version"}, "v1.2.4-dev.2"},
+	}
+	for _, tt := range tests {
+		got, err := runArgs(t, append([]string{"--path", dir, "--describe"}, tt.args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%v: got %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	other := newRepo(t)
+	commit(t, other, "initial")
+	runGit(t, other, "tag", "nightly")
+	if _, err := runArgs(t, "--path", other, "--describe"); err == nil || !strings.Contains(err.Error(), "failed to describe HEAD") {
+		t.Errorf("error = %v, want a describe failure", err)
+	}
+}
+
+func TestDescribeFormatTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		opts    Options
+		format  string
+		dev     string
+		wantErr bool
+	}{
+		{name: "default", output: "v1.2.3-2-gabc1234", format: "base=v1.2.3 distance=2 sha=abc1234", dev: "v1.2.4-dev.2"},
+		{name: "exact", output: "v1.2.3", format: "base=v1.2.3 distance=0 sha=", dev: "v1.2.3"},
+		{name: "prefix", output: "api-v1.2.3-5-gabc1234", opts: Options{Prefix: "api-v"}, format: "base=api-v1.2.3 distance=5 sha=abc1234", dev: "api-v1.2.4-dev.5"},
+		{name: "prefix case", output: "v1.2.3-1-gabc1234", opts: Options{PrefixCase: "upper"}, format: "base=V1.2.3 distance=1 sha=abc1234", dev: "V1.2.4-dev.1"},
+		{name: "two components", output: "v1.2-1-gabc1234", opts: Options{Components: 2}, format: "base=v1.2 distance=1 sha=abc1234", dev: "v1.3-dev.1"},
+		{name: "four components", output: "v1.2.3.4-1-gabc1234", opts: Options{Components: 4}, format: "base=v1.2.3.4 distance=1 sha=abc1234", dev: "v1.2.3.5-dev.1"},
+		{name: "prerelease", output: "v1.3-rc.1-2-gabc1234", opts: Options{Components: 2, Prereleases: true}, format: "base=v1.3-rc.1 distance=2 sha=abc1234", dev: "v1.3-dev.2"},
+		{name: "other prefix", output: "v1.2.3-1-gabc1234", opts: Options{Prefix: "api-v"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDescribe(tt.output, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := d.Format(tt.opts); got != tt.format {
+				t.Errorf("Format = %q, want %q", got, tt.format)
+			}
+			if got := d.DevVersion(tt.opts); got != tt.dev {
+				t.Errorf("DevVersion = %q, want %q", got, tt.dev)
+			}
+		})
+	}
+}
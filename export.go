@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type exportedTag struct {
+	Raw        string `json:"raw"`
+	Major      int    `json:"major"`
+	Minor      int    `json:"minor"`
+	Patch      int    `json:"patch"`
+	Prerelease string `json:"prerelease,omitempty"`
+	Edition    string `json:"edition,omitempty"`
+}
+
+// readExportedTags returns the semver tags of --path, highest first
+func readExportedTags(opts Options) ([]exportedTag, error) {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return nil, err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return nil, err
+	}
+
+	tags, err := getSemverTags(opts.Path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := []exportedTag{}
+	for _, tag := range tags {
+		// Skip the v0.0.0 starting point used when no tags exist
+		if tag.Raw == "" {
+			continue
+		}
+		exported = append(exported, exportedTag{Raw: tag.Raw, Major: tag.Major, Minor: tag.Minor, Patch: tag.Patch, Prerelease: tag.Prerelease, Edition: tag.Edition})
+	}
+	return exported, nil
+}
+
+func runExportTags(opts Options) error {
+	exported, err := readExportedTags(opts)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if opts.ExportFormat == "yaml" {
+		data = marshalTagsYAML(exported)
+	} else if data, err = json.MarshalIndent(exported, "", "  "); err != nil {
+		return err
+	} else {
+		data = append(data, '\n')
+	}
+
+	if err := os.WriteFile(opts.ExportTags, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.ExportTags, err)
+	}
+	return nil
+}
+
+// runListJSON prints the semver tags as a JSON array, highest first
+func runListJSON(opts Options, out io.Writer) error {
+	exported, err := readExportedTags(opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+func marshalTagsYAML(tags []exportedTag) []byte {
+	if len(tags) == 0 {
+		return []byte("[]\n")
+	}
+
+	var b strings.Builder
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "- raw: %s\n", strconv.Quote(tag.Raw))
+		fmt.Fprintf(&b, "  major: %d\n  minor: %d\n  patch: %d\n", tag.Major, tag.Minor, tag.Patch)
+		if tag.Prerelease != "" {
+			fmt.Fprintf(&b, "  prerelease: %s\n", strconv.Quote(tag.Prerelease))
+		}
+		if tag.Edition != "" {
+			fmt.Fprintf(&b, "  edition: %s\n", strconv.Quote(tag.Edition))
+		}
+	}
+	return []byte(b.String())
+}
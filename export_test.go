@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportTags(t *testing.T) {
+	dir := newRepo(t, "v1.2.0", "v1.3.0-rc.1", "latest", "v1.2.10")
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"json", `[
+  {
+    "raw": "v1.3.0-rc.1",
+    "major": 1,
+    "minor": 3,
+    "patch": 0,
+    "prerelease": "rc.1"
+  },
+  {
+    "raw": "v1.2.10",
+    "major": 1,
+    "minor": 2,
+    "patch": 10
+  },
+  {
+    "raw": "v1.2.0",
+    "major": 1,
+    "minor": 2,
+    "patch": 0
+  }
+]
+`},
+		{"yaml", `- raw: "v1.3.0-rc.1"
+  major: 1
+  minor: 3
+  patch: 0
+  prerelease: "rc.1"
+- raw: "v1.2.10"
+  major: 1
+  minor: 2
+  patch: 10
+- raw: "v1.2.0"
+  major: 1
+  minor: 2
+  patch: 0
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tags."+tt.format)
+			if _, err := runArgs(t, "--path", dir, "--export-tags", path, "--export-format", tt.format, "--prereleases"); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportTagsEmpty(t *testing.T) {
+	dir := newRepo(t)
+	commit(t, dir, "initial")
+	for format, want := range map[string]string{"json": "[]\n", "yaml": "[]\n"} {
+		path := filepath.Join(t.TempDir(), "tags")
+		if _, err := runArgs(t, "--path", dir, "--export-tags", path, "--export-format", format); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := os.ReadFile(path)
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", format, got, want)
+		}
+		if format == "json" {
+			var tags []exportedTag
+			if err := json.Unmarshal(got, &tags); err != nil || len(tags) != 0 {
+				t.Errorf("json: %v %v", tags, err)
+			}
+		}
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		args []string
+		want []exportedTag
+	}{
+		{"highest first", []string{"v1.2.0", "v1.3.0-rc.1", "latest", "v1.2.10"}, nil, []exportedTag{
+			{Raw: "v1.2.10", Major: 1, Minor: 2, Patch: 10},
+			{Raw: "v1.2.0", Major: 1, Minor: 2, Patch: 0},
+		}},
+		{"prereleases", []string{"v1.2.0", "v1.3.0-rc.1", "latest", "v1.2.10"}, []string{"--prereleases"}, []exportedTag{
+			{Raw: "v1.3.0-rc.1", Major: 1, Minor: 3, Patch: 0, Prerelease: "rc.1"},
+			{Raw: "v1.2.10", Major: 1, Minor: 2, Patch: 10},
+			{Raw: "v1.2.0", Major: 1, Minor: 2, Patch: 0},
+		}},
+		{"no tags", nil, nil, []exportedTag{}},
+		{"ignored tags", []string{"v1.0.0", "v2.0.0", "v2.1.0"}, []string{"--tag-ignore", "^v1\\."}, []exportedTag{
+			{Raw: "v2.1.0", Major: 2, Minor: 1, Patch: 0},
+			{Raw: "v2.0.0", Major: 2, Minor: 0, Patch: 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			if len(tt.tags) == 0 {
+				commit(t, dir, "initial")
+			}
+			got, err := runArgs(t, append([]string{"--path", dir, "--list-json"}, tt.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(got, "]\n") {
+				t.Errorf("output %q is not a single JSON line", got)
+			}
+			var tags []exportedTag
+			if err := json.Unmarshal([]byte(got), &tags); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tags, tt.want) {
+				t.Errorf("got %+v, want %+v", tags, tt.want)
+			}
+		})
+	}
+}
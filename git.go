@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitCommand prepares a git invocation running inside the repository at dir
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// gitBackend reads repositories for --backend. Only the repository check and
+// tag listing go through it; other git features always run the git binary.
+type gitBackend struct {
+	checkRepo func(path string) error
+	listTags  func(path, ref string, opts Options) ([]string, error)
+}
+
+// backends maps --backend names to their implementation; builds with the
+// gogit tag add go-git
+var backends = map[string]gitBackend{
+	"git": {checkRepo: execCheckRepo, listTags: execListTags},
+}
+
+// backend is the implementation selected by run
+var backend = backends["git"]
+
+func checkIfGitRepo(path string) error {
+	return backend.checkRepo(path)
+}
+
+func execCheckRepo(path string) error {
+	cmd := gitCommand(path, "rev-parse", "--is-inside-work-tree")
+	output, err := cmd.CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "true" {
+		return fmt.Errorf("path %s is not a Git repository", path)
+	}
+	return nil
+}
+
+// getCurrentBranch returns the checked out branch, naming the flag that
+// needs it in the error when HEAD is detached
+func getCurrentBranch(path, feature string) (string, error) {
+	cmd := gitCommand(path, "rev-parse", "--abbrev-ref", "HEAD")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached; %s requires a branch", feature)
+	}
+	return branch, nil
+}
+
+// countCommitsSince returns the number of commits on HEAD since the given tag,
+// or all commits on HEAD if tag is empty
+func countCommitsSince(path, tag string) (int, error) {
+	rev := "HEAD"
+	if tag != "" {
+		rev = tag + "..HEAD"
+	}
+
+	cmd := gitCommand(path, "rev-list", "--count", rev)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits since %s: %w", tag, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+func checkCleanWorktree(path string) error {
+	cmd := gitCommand(path, "status", "--porcelain")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	if status := strings.TrimSpace(string(output)); status != "" {
+		return fmt.Errorf("working tree at %s has uncommitted changes:\n%s", path, status)
+	}
+	return nil
+}
+
+// listSubmodules returns the paths of the submodules of the repository at path,
+// relative to it
+func listSubmodules(path string) ([]string, error) {
+	cmd := gitCommand(path, "submodule", "foreach", "--quiet", "echo $sm_path")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+	var submodules []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			submodules = append(submodules, line)
+		}
+	}
+	return submodules, nil
+}
+
+// resolveRev returns the commit hash rev points at
+func resolveRev(path, rev string) (string, error) {
+	cmd := gitCommand(path, "rev-parse", "--verify", rev+"^{commit}")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w: %s", rev, err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// createTag creates a lightweight tag at HEAD; git refuses existing names
+func createTag(path, name string) error {
+	cmd := gitCommand(path, "tag", name, "HEAD")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// listHeadTags returns the tags pointing at HEAD
+func listHeadTags(path string) ([]string, error) {
+	cmd := gitCommand(path, "tag", "--points-at", "HEAD")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags at HEAD: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// latestTagByTopology returns the most recent tag reachable from HEAD that is
+// one of the given semver tags, skipping any other tags in between
+func latestTagByTopology(path string, tags []SemVer) (SemVer, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	for {
+		cmd := gitCommand(path, args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return SemVer{}, fmt.Errorf("no semver tag is reachable from HEAD: %s", strings.TrimSpace(string(output)))
+		}
+		name := strings.TrimSpace(string(output))
+		for _, tag := range tags {
+			if tag.Raw == name {
+				return tag, nil
+			}
+		}
+		args = append(args, "--exclude", name)
+	}
+}
+
+// isReachable reports whether rev is an ancestor of HEAD; revs that do not
+// resolve to a commit are never reachable
+func isReachable(path, rev string) bool {
+	cmd := gitCommand(path, "merge-base", "--is-ancestor", rev, "HEAD")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		slog.Debug("tag is not reachable from HEAD", "tag", rev, "output", strings.TrimSpace(string(output)))
+		return false
+	}
+	return true
+}
+
+// readBumpTrailer returns the last value of the key trailer in the HEAD
+// commit message, or an empty string when the trailer is absent
+func readBumpTrailer(path, key string) (string, error) {
+	cmd := gitCommand(path, "log", "-1", "--format=%(trailers:key="+key+",valueonly)")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the %s trailer: %w: %s", key, err, strings.TrimSpace(string(output)))
+	}
+	// git prints one value per line, so the last trailer wins
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// tagCommitTime returns the committer date of the commit tag points at
+func tagCommitTime(path, tag string) (time.Time, error) {
+	cmd := gitCommand(path, "log", "-1", "--format=%ct", tag)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get the date of tag %s: %w: %s", tag, err, strings.TrimSpace(string(output)))
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the date of tag %s: %w", tag, err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+func verifyTagSignature(path, tag string) error {
+	cmd := gitCommand(path, "tag", "-v", tag)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("signature verification failed for tag %s: %w: %s", tag, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func parseReleaseBranch(branch string) (int, int, error) {
+	branchRegex := regexp.MustCompile(`^release/(\d+)\.(\d+)$`)
+	matches := branchRegex.FindStringSubmatch(branch)
+	if matches == nil {
+		return 0, 0, fmt.Errorf("branch %s does not match release/<major>.<minor>", branch)
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return major, minor, nil
+}
+
+// listGitTags returns the tag names of the repository at path, without the
+// refs/tags/ prefix
+func listGitTags(path string, opts Options) ([]string, error) {
+	return listMergedGitTags(path, "", opts)
+}
+
+// listMergedGitTags is listGitTags restricted to tags reachable from ref,
+// unless ref is empty
+func listMergedGitTags(path, ref string, opts Options) ([]string, error) {
+	return backend.listTags(path, ref, opts)
+}
+
+func execListTags(path, ref string, opts Options) ([]string, error) {
+	args := []string{"tag", "--list"}
+	if opts.TagNamespace != "" || len(opts.Taggers) > 0 {
+		pattern := "refs/tags"
+		if opts.TagNamespace != "" {
+			pattern += "/" + strings.Trim(opts.TagNamespace, "/") + "/"
+		}
+		args = []string{"for-each-ref", "--format=%(refname)%09%(taggername)", pattern}
+	}
+	if opts.MaxTags > 0 {
+		// Rely on git's version sort so the highest tags come first
+		args = append(args, "--sort=-v:refname")
+	}
+	if ref != "" {
+		args = append(args, "--merged", ref)
+	}
+	cmd := gitCommand(path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// Keep stdout apart from warnings so tags printed before a nonzero exit stay usable
+	output, gitErr := cmd.Output()
+
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		ref, tagger, _ := strings.Cut(line, "\t")
+		name := strings.TrimPrefix(strings.TrimSpace(ref), "refs/tags/")
+		if name == "" {
+			continue
+		}
+		if len(opts.Taggers) > 0 && !slices.Contains(opts.Taggers, strings.TrimSpace(tagger)) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if gitErr != nil {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("failed to get tags: %w: %s", gitErr, strings.TrimSpace(stderr.String()))
+		}
+		slog.Warn("git exited with an error but listed tags; continuing with them", "err", gitErr, "stderr", strings.TrimSpace(stderr.String()), "tags", len(names))
+	}
+	if len(names) == 0 && len(output) > 0 {
+		// Some git configurations print blank lines when there are no tags
+		slog.Debug("git returned output but no tags parsed", "bytes", len(output))
+	}
+	return names, nil
+}
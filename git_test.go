@@ -0,0 +1,422 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseReleaseBranch(t *testing.T) {
+	tests := []struct {
+		branch       string
+		major, minor int
+		wantErr      bool
+	}{
+		{branch: "release/1.3", major: 1, minor: 3},
+		{branch: "release/10.20", major: 10, minor: 20},
+		{branch: "release/1", wantErr: true},
+		{branch: "main", wantErr: true},
+		{branch: "hotfix/1.3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			major, minor, err := parseReleaseBranch(tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (major != tt.major || minor != tt.minor) {
+				t.Errorf("got %d.%d, want %d.%d", major, minor, tt.major, tt.minor)
+			}
+		})
+	}
+}
+
+func TestFromBranch(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+
+	runGit(t, dir, "checkout", "-q", "-b", "release/1.3")
+	got, err := runArgs(t, "--path", dir, "--from-branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.3.0" {
+		t.Errorf("got %q, want v1.3.0", got)
+	}
+
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	if _, err := runArgs(t, "--path", dir, "--from-branch"); err == nil {
+		t.Error("expected an error on a branch that is not release/<major>.<minor>")
+	}
+}
+
+func TestDetachedHead(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	commit(t, dir, "work")
+	runGit(t, dir, "checkout", "-q", "--detach", "v1.2.3")
+
+	for _, flag := range []string{"--from-branch", "--branch-aware"} {
+		t.Run(flag, func(t *testing.T) {
+			args := []string{"--path", dir, flag}
+			if flag == "--branch-aware" {
+				args = append(args, "--major", "1", "--minor", "2")
+			}
+			_, err := runArgs(t, args...)
+			if want := "HEAD is detached; " + flag + " requires a branch"; err == nil || err.Error() != want {
+				t.Errorf("error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr string
+	}{
+		{"no tags", nil, ""},
+		{"lightweight tag", []string{"v1.0.0"}, "signature verification failed for tag v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			commit(t, dir, "change")
+			_, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "0", "--verify-signature")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		dirty   bool
+		wantErr bool
+	}{
+		{"clean", false, false},
+		{"untracked file", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.0.0")
+			if tt.dirty {
+				writeFileAt(t, dir+"/notes.txt", "wip\n")
+			}
+			_, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "0", "--require-clean")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "notes.txt") {
+				t.Errorf("error %q does not list the change", err)
+			}
+		})
+	}
+}
+
+// fakeGit puts a git on PATH that runs script instead of the real binary
+func fakeGit(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestListTagsWhitespaceOnly(t *testing.T) {
+	fakeGit(t, `printf '\n  \n\t\n'`)
+	logs := captureLog(t)
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	names, err := execListTags(t.TempDir(), "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Errorf("got tags %q, want none", names)
+	}
+	if !strings.Contains(logs.String(), "git returned output but no tags parsed") {
+		t.Errorf("missing debug log: %s", logs)
+	}
+
+	tags, err := parseSemverTags(names, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].String() != "v0.0.0" {
+		t.Errorf("got %v, want the v0.0.0 starting point", tags)
+	}
+}
+
+func TestIncludeSubmodules(t *testing.T) {
+	api := newRepo(t, "v1.0.0")
+	web := newRepo(t, "v1.4.2")
+	super := newRepo(t)
+	commit(t, super, "initial")
+	for name, repo := range map[string]string{"api": api, "web": web} {
+		runGit(t, super, "-c", "protocol.file.allow=always", "submodule", "--quiet", "add", repo, name)
+	}
+	commit(t, super, "add submodules")
+
+	got, err := runArgs(t, "--path", super, "--include-submodules", "--major", "1", "--minor", "4")
+	if err == nil {
+		t.Fatal("expected an error for the api submodule, which cannot skip to 1.4")
+	}
+	if !strings.Contains(err.Error(), filepath.Join(super, "api")) {
+		t.Errorf("error %q does not name the api submodule", err)
+	}
+	if want := filepath.Join(super, "web") + " v1.4.3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTagger(t *testing.T) {
+	dir := newRepo(t, "v1.0.0")
+	for _, tag := range [][2]string{{"Alice", "v1.1.0"}, {"Mallory", "v1.2.0"}, {"Bob Builder", "v1.1.1"}} {
+		commit(t, dir, "release "+tag[1])
+		runGit(t, dir, "-c", "user.name="+tag[0], "tag", "-a", "-m", tag[1], tag[1])
+	}
+	tests := []struct {
+		taggers []string
+		want    string
+	}{
+		{[]string{"Alice"}, "v1.1.0"},
+		{[]string{"Alice", "Bob Builder"}, "v1.1.1,v1.1.0"},
+		{[]string{"Eve"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.taggers, ","), func(t *testing.T) {
+			tags, err := getSemverTags(dir, Options{Taggers: tt.taggers})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got, err := runArgs(t, "--path", dir, "--tagger", "Alice", "--tagger", "Bob Builder", "--major", "1", "--minor", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.1.2" {
+		t.Errorf("got %q, want v1.1.2", got)
+	}
+}
+
+func TestExcludeHeadTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude bool
+		want    string
+	}{
+		{"re-release", true, "v1.2.3"},
+		{"default", false, "v1.2.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.2", "v1.2.3")
+			args := []string{"--path", dir, "--major", "1", "--minor", "2"}
+			if tt.exclude {
+				args = append(args, "--exclude-head-tag")
+			}
+			got, err := runArgs(t, args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestByTopology(t *testing.T) {
+	dir := newRepo(t, "v1.4.0")
+	runGit(t, dir, "checkout", "-q", "-b", "next")
+	commit(t, dir, "release v2.0.0")
+	runGit(t, dir, "tag", "v2.0.0")
+	runGit(t, dir, "checkout", "-q", "main")
+	commit(t, dir, "release v1.3.1")
+	runGit(t, dir, "tag", "v1.3.1")
+	commit(t, dir, "deploy")
+	runGit(t, dir, "tag", "deploy-42")
+
+	tests := []struct {
+		latestBy     string
+		major, minor string
+		want         string
+	}{
+		{"topology", "1", "3", "v1.3.2"},
+		{"semver", "2", "0", "v2.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latestBy, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--latest-by", tt.latestBy, "--major", tt.major, "--minor", tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	unreachable := newRepo(t)
+	commit(t, unreachable, "initial")
+	runGit(t, unreachable, "checkout", "-q", "-b", "side")
+	commit(t, unreachable, "release v1.0.0")
+	runGit(t, unreachable, "tag", "v1.0.0")
+	runGit(t, unreachable, "checkout", "-q", "main")
+	if _, err := runArgs(t, "--path", unreachable, "--latest-by", "topology", "--major", "1", "--minor", "0"); err == nil || !strings.Contains(err.Error(), "no semver tag is reachable from HEAD") {
+		t.Errorf("error = %v, want no reachable tag", err)
+	}
+}
+
+func TestReachableOnly(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	runGit(t, dir, "checkout", "-q", "-b", "abandoned")
+	commit(t, dir, "release v1.3.0")
+	runGit(t, dir, "tag", "v1.3.0")
+	runGit(t, dir, "checkout", "-q", "main")
+
+	tests := []struct {
+		reachableOnly bool
+		want          string
+	}{
+		{true, "v1.2.3"},
+		{false, "v1.3.0,v1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatBool(tt.reachableOnly), func(t *testing.T) {
+			tags, err := getSemverTags(dir, Options{ReachableOnly: tt.reachableOnly})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncFrom(t *testing.T) {
+	dir := newRepo(t, "v0.1.0")
+	source := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name    string
+		from    string
+		want    string
+		wantErr string
+	}{
+		{"other repository", source, "v1.2.4", ""},
+		{"missing", filepath.Join(source, "missing"), "", "--sync-from: path"},
+		{"not a repository", t.TempDir(), "", "--sync-from: path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--sync-from", tt.from, "--major", "1", "--minor", "2")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListTagsPartialOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+		wantLog string
+	}{
+		{"tags before an error", "printf 'v1.2.3\\nv1.3.0\\n'; echo 'warning: bad ref' >&2; exit 1", "v1.2.3,v1.3.0", "", "git exited with an error but listed tags"},
+		{"error without tags", "echo 'fatal: broken' >&2; exit 128", "", "failed to get tags: exit status 128: fatal: broken", ""},
+		{"warning on success", "printf 'v1.2.3\\n'; echo 'warning: slow' >&2", "v1.2.3", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGit(t, tt.script)
+			logs := captureLog(t)
+			names, err := execListTags(t.TempDir(), "", Options{})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if (tt.wantLog == "" && logs.Len() > 0) || !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("logged %q, want %q", logs, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestBumpTrailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"patch", "fix\n\nVersion-Bump: patch", nil, "v1.2.4", ""},
+		{"minor", "feature\n\nVersion-Bump: minor", nil, "v1.3.0", ""},
+		{"major", "breaking\n\nVersion-Bump: Major", nil, "v2.0.0", ""},
+		{"trailer overrides flags", "feature\n\nVersion-Bump: minor", []string{"--major", "1", "--minor", "2"}, "v1.3.0", ""},
+		{"fallback to flags", "chore", []string{"--major", "1", "--minor", "2"}, "v1.2.4", ""},
+		{"missing", "chore", nil, "", "HEAD has no Version-Bump trailer and neither --major nor --minor is given"},
+		{"invalid", "feature\n\nVersion-Bump: huge", nil, "", `invalid Version-Bump trailer "huge": must be patch, minor or major`},
+		{"several words", "feature\n\nVersion-Bump: minor change", nil, "", `invalid Version-Bump trailer "minor change": must be patch, minor or major`},
+		{"last trailer wins", "feature\n\nVersion-Bump: major\nVersion-Bump: minor", nil, "v1.3.0", ""},
+		{"last trailer is checked", "feature\n\nVersion-Bump: minor\nVersion-Bump: not a patch", nil, "", `invalid Version-Bump trailer "not a patch": must be patch, minor or major`},
+		{"zerover keeps stable majors", "breaking\n\nVersion-Bump: major", []string{"--zerover"}, "v2.0.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.3")
+			commit(t, dir, tt.message)
+			got, err := runArgs(t, append([]string{"--path", dir, "--bump-trailer", "Version-Bump"}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
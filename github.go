@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// githubAPIURL and httpClient are variables so the API can be pointed elsewhere
+var (
+	githubAPIURL = "https://api.github.com"
+	httpClient   = &http.Client{Timeout: 30 * time.Second}
+)
+
+var nextLinkRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// fetchGitHubTags lists the tag names of a GitHub repository through the
+// REST API, following pagination
+func fetchGitHubTags(repo, token string) ([]string, error) {
+	if !regexp.MustCompile(`^[\w.-]+/[\w.-]+$`).MatchString(repo) {
+		return nil, fmt.Errorf("invalid --github-repo %q: expected owner/name", repo)
+	}
+
+	var names []string
+	url := githubAPIURL + "/repos/" + repo + "/tags?per_page=100"
+	for url != "" {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tags of %s: %w", repo, err)
+		}
+		if err := checkGitHubResponse(resp, repo); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var page []struct {
+			Name string `json:"name"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %w", repo, err)
+		}
+		for _, tag := range page {
+			names = append(names, tag.Name)
+		}
+
+		url = ""
+		if matches := nextLinkRegex.FindStringSubmatch(resp.Header.Get("Link")); matches != nil {
+			url = matches[1]
+		}
+	}
+	return names, nil
+}
+
+func checkGitHubResponse(resp *http.Response, repo string) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		msg := "GitHub API rate limit exceeded"
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			msg += "; it resets at " + time.Unix(reset, 0).UTC().Format(time.RFC3339)
+		}
+		return errors.New(msg + " (pass --github-token to raise the limit)")
+	}
+	return fmt.Errorf("failed to fetch tags of %s: GitHub API returned %s", repo, resp.Status)
+}
+
+// writeGitHubOutput appends the version outputs to the file named by
+// GITHUB_OUTPUT, as expected by GitHub Actions steps
+func writeGitHubOutput(v SemVer, opts Options) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return errors.New("--github-output requires the GITHUB_OUTPUT environment variable to be set")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_OUTPUT file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "version=%s\nmajor=%d\nminor=%d\npatch=%d\n", FormatTag(v, opts), v.Major, v.Minor, v.Patch)
+	if err != nil {
+		return fmt.Errorf("failed to write GITHUB_OUTPUT file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeGitHub serves the tags of owner/name in pages of two and points the
+// API at it for the rest of the test
+func fakeGitHub(t *testing.T, tags ...string) {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") == "Bearer limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		case r.URL.Path != "/repos/owner/name/tags":
+			http.NotFound(w, r)
+			return
+		}
+		var page int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		end := min(2*page+2, len(tags))
+		if end < len(tags) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/name/tags?page=%d>; rel="next"`, server.URL, page+1))
+		}
+		var names []string
+		for _, tag := range tags[2*page : end] {
+			names = append(names, fmt.Sprintf(`{"name":%q}`, tag))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(names, ","))
+	}))
+	t.Cleanup(server.Close)
+	defaultURL := githubAPIURL
+	t.Cleanup(func() { githubAPIURL = defaultURL })
+	githubAPIURL = server.URL
+}
+
+func TestFetchGitHubTags(t *testing.T) {
+	fakeGitHub(t, "v1.0.0", "v1.1.0", "docs", "v1.1.1", "v2.0.0-rc.1")
+	tests := []struct {
+		repo, token string
+		want        string
+		wantErr     string
+	}{
+		{repo: "owner/name", want: "v1.0.0,v1.1.0,docs,v1.1.1,v2.0.0-rc.1"},
+		{repo: "owner/missing", wantErr: "GitHub API returned 404"},
+		{repo: "owner/name", token: "limited", wantErr: "rate limit exceeded; it resets at 1970-01-01T00:00:00Z"},
+		{repo: "owner", wantErr: "expected owner/name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo+"/"+tt.token, func(t *testing.T) {
+			names, err := fetchGitHubTags(tt.repo, tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	fakeGitHub(t, "v1.0.0", "v1.1.0", "v1.1.1")
+	got, err := runArgs(t, "--github-repo", "owner/name", "--major", "1", "--minor", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.0" {
+		t.Errorf("got %q, want v1.2.0", got)
+	}
+}
+
+func TestWriteGitHubOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+	writeFileAt(t, path, "earlier=1\n")
+
+	if err := writeGitHubOutput(SemVer{Major: 1, Minor: 3, Patch: 0}, Options{PrefixCase: "upper"}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	if want := "earlier=1\nversion=V1.3.0\nmajor=1\nminor=3\npatch=0\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteGitHubOutputUnset(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	if err := writeGitHubOutput(Zero(), Options{}); err == nil {
+		t.Error("expected an error without GITHUB_OUTPUT")
+	}
+}
@@ -0,0 +1,106 @@
+//go:build gogit
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func init() {
+	backends["go-git"] = gitBackend{checkRepo: goGitCheckRepo, listTags: goGitListTags}
+}
+
+func openGoGitRepo(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("path %s is not a Git repository", path)
+	}
+	return repo, nil
+}
+
+func goGitCheckRepo(path string) error {
+	_, err := openGoGitRepo(path)
+	return err
+}
+
+// goGitListTags mirrors execListTags without running git
+func goGitListTags(path, ref string, opts Options) ([]string, error) {
+	repo, err := openGoGitRepo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *object.Commit
+	if ref != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		if target, err = repo.CommitObject(*hash); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+	}
+
+	namespacePrefix := ""
+	if opts.TagNamespace != "" {
+		namespacePrefix = strings.Trim(opts.TagNamespace, "/") + "/"
+	}
+
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	var names []string
+	err = refs.ForEach(func(tagRef *plumbing.Reference) error {
+		name := tagRef.Name().Short()
+		if !strings.HasPrefix(name, namespacePrefix) {
+			return nil
+		}
+
+		// Annotated tags point at a tag object, lightweight ones at a commit
+		commitHash := tagRef.Hash()
+		tagger := ""
+		if tag, err := repo.TagObject(tagRef.Hash()); err == nil {
+			tagger = tag.Tagger.Name
+			commitHash = tag.Target
+		} else if !errors.Is(err, plumbing.ErrObjectNotFound) {
+			return err
+		}
+		if len(opts.Taggers) > 0 && !slices.Contains(opts.Taggers, tagger) {
+			return nil
+		}
+
+		if target != nil {
+			commit, err := repo.CommitObject(commitHash)
+			if err != nil {
+				// Tags of non-commit objects are never merged
+				return nil
+			}
+			if commit.Hash != target.Hash {
+				if merged, err := commit.IsAncestor(target); err != nil || !merged {
+					return nil
+				}
+			}
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	if opts.MaxTags > 0 {
+		// Match git's -v:refname sort so the highest tags come first
+		slices.SortFunc(names, func(a, b string) int { return compareVersionNames(b, a) })
+	} else {
+		slices.Sort(names)
+	}
+	return names, nil
+}
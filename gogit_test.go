@@ -0,0 +1,66 @@
+//go:build gogit
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGoGitListTagsMatchesGit(t *testing.T) {
+	dir := newRepo(t, "v1.0.0", "services/api/v1.1.0")
+	commit(t, dir, "release v1.2.0")
+	runGit(t, dir, "-c", "user.name=Alice", "tag", "-a", "-m", "v1.2.0", "v1.2.0")
+	runGit(t, dir, "checkout", "-q", "-b", "side")
+	commit(t, dir, "release v2.0.0")
+	runGit(t, dir, "tag", "v2.0.0")
+	runGit(t, dir, "checkout", "-q", "main")
+
+	tests := []struct {
+		name string
+		ref  string
+		opts Options
+	}{
+		{"all", "", Options{}},
+		{"merged", "HEAD", Options{}},
+		{"merged into side", "side", Options{}},
+		{"namespace", "", Options{TagNamespace: "services/api"}},
+		{"tagger", "", Options{Taggers: []string{"Alice"}}},
+		{"version sort", "", Options{MaxTags: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := execListTags(dir, tt.ref, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := goGitListTags(dir, tt.ref, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.opts.MaxTags == 0 {
+				slices.Sort(want)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("go-git listed %q, git listed %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGoGitBackend(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	got, err := runArgs(t, "--path", dir, "--backend", "go-git", "--major", "1", "--minor", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4" {
+		t.Errorf("got %q, want v1.2.4", got)
+	}
+
+	_, err = runArgs(t, "--path", t.TempDir(), "--backend", "go-git", "--major", "1", "--minor", "2")
+	if err == nil || !strings.Contains(err.Error(), "is not a Git repository") {
+		t.Errorf("error = %v, want not a Git repository", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// beforeBump runs the guards of an actual bump, after the version is computed
+// and before anything is written or tagged
+func beforeBump(latestTag, nextVersion SemVer, opts Options) error {
+	if opts.ConfirmMajor {
+		if err := confirmMajorBump(latestTag, nextVersion, opts); err != nil {
+			return err
+		}
+	}
+	if opts.PreBumpScript != "" {
+		return runPreBumpScript(opts.PreBumpScript, FormatTag(nextVersion, opts))
+	}
+	return nil
+}
+
+// runPreBumpScript runs the user's command through sh, so it may carry its
+// own arguments, with the proposed version appended as the last argument and
+// in SEMVER_VERSION; a non-zero exit rejects the bump
+func runPreBumpScript(script, version string) error {
+	cmd := exec.Command("sh", "-c", script+` "$@"`, "sh", version)
+	cmd.Env = append(os.Environ(), "SEMVER_VERSION="+version)
+	// Keep stdout free for the computed version
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pre-bump script %s rejected %s: %w", script, version, err)
+	}
+	return nil
+}
+
+// confirmMajorBump guards a major bump behind --i-mean-it, or a y/N prompt
+// when stdin is a terminal
+func confirmMajorBump(latestTag, nextVersion SemVer, opts Options) error {
+	if opts.IMeanIt || BumpKind(latestTag, nextVersion) != "major" {
+		return nil
+	}
+	from, to := FormatTag(latestTag, opts), FormatTag(nextVersion, opts)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Bump the major version from %s to %s? [y/N] ", from, to)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+			return nil
+		}
+	}
+	return fmt.Errorf("major bump from %s to %s was not confirmed; pass --i-mean-it", from, to)
+}
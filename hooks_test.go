@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreBumpScript(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name    string
+		script  string
+		args    string
+		want    string
+		wantErr string
+	}{
+		{"accepts", `test "$1" = v1.2.4 && test "$SEMVER_VERSION" = v1.2.4`, "", "v1.2.4", ""},
+		{"rejects", "exit 3", "", "", "pre-bump script"},
+		{"arguments", `test "$#" = 3 && test "$1" = --policy && test "$2" = "no fridays" && test "$3" = v1.2.4`, ` --policy "no fridays"`, "v1.2.4", ""},
+		{"arguments reject", `test "$1" = --allow`, " --deny", "", "pre-bump script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := filepath.Join(t.TempDir(), "check.sh")
+			if err := os.WriteFile(script, []byte("#!/bin/sh\n"+tt.script+"\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--pre-bump-script", script+tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if got != "" {
+					t.Errorf("printed %q after a rejected bump", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmMajor(t *testing.T) {
+	// Without a terminal on stdin nothing prompts
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defaultStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = defaultStdin })
+	os.Stdin = stdin
+
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"unconfirmed major", []string{"--major", "2", "--minor", "0"}, "", "major bump from v1.2.3 to v2.0.0 was not confirmed; pass --i-mean-it"},
+		{"confirmed major", []string{"--major", "2", "--minor", "0", "--i-mean-it"}, "v2.0.0", ""},
+		{"minor", []string{"--major", "1", "--minor", "3"}, "v1.3.0", ""},
+		{"validate only", []string{"--major", "2", "--minor", "0", "--validate-only"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--confirm-major"}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
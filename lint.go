@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// versionLikeRegex matches tag names that look like a version, whatever their format
+var versionLikeRegex = regexp.MustCompile(`^(\D*?)(\d+(?:\.\d+)+)(.*)$`)
+
+// runLint reports every version-like tag that violates the configured format
+func runLint(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+
+	names, err := listGitTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	violations := 0
+	for _, name := range names {
+		for _, problem := range lintTag(name, opts) {
+			fmt.Fprintf(out, "%s: %s\n", name, problem)
+			violations++
+		}
+	}
+	if violations > 0 {
+		return fmt.Errorf("found %d tag format violations", violations)
+	}
+	return nil
+}
+
+// lintTag returns the format problems of a tag, or nothing if the tag is
+// valid or does not look like a version at all
+func lintTag(name string, opts Options) []string {
+	if opts.TagNamespace != "" {
+		name = strings.TrimPrefix(name, strings.Trim(opts.TagNamespace, "/")+"/")
+	}
+	matches := versionLikeRegex.FindStringSubmatch(name)
+	if matches == nil {
+		return nil
+	}
+
+	var problems []string
+	prefix := "v"
+	if opts.Prefix != "" {
+		prefix = opts.Prefix
+	}
+	if matches[1] != prefix {
+		problems = append(problems, fmt.Sprintf("prefix %q, expected %q", matches[1], prefix))
+	}
+
+	components := opts.Components
+	if components == 0 {
+		components = 3
+	}
+	numbers := strings.Split(matches[2], ".")
+	if len(numbers) != components {
+		problems = append(problems, fmt.Sprintf("%d components, expected %d", len(numbers), components))
+	}
+	for _, n := range numbers {
+		if len(n) > 1 && n[0] == '0' {
+			problems = append(problems, fmt.Sprintf("leading zero in %q", n))
+		}
+	}
+	return problems
+}
+
+// runCheckMonotonic reports every pair of consecutive tags where the later
+// one is not a version the configured strategy would have produced
+func runCheckMonotonic(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+	strategy, err := strategyByName(opts.Strategy)
+	if err != nil {
+		return err
+	}
+
+	tags, err := getSemverTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	violations := 0
+	// Tags are sorted highest first; walk them from the oldest version up
+	for i := len(tags) - 1; i > 0; i-- {
+		prev, tag := tags[i], tags[i-1]
+		if prev.Raw == "" || Compare(prev, tag) == 0 {
+			continue
+		}
+		expected, err := strategy.Next(prev, tag.Major, tag.Minor)
+		if opts.Components != 0 {
+			expected = applyComponents(prev, expected, opts.Components)
+		}
+		// A prerelease is a step towards its release version
+		release := tag
+		release.Prerelease = ""
+		if err == nil && Compare(expected, release) != 0 {
+			err = fmt.Errorf("expected %s", expected)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%s -> %s: %s\n", prev.Raw, tag.Raw, err)
+			violations++
+		}
+	}
+	if violations > 0 {
+		return fmt.Errorf("found %d non-contiguous tag pairs", violations)
+	}
+	return nil
+}
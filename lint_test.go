@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintTag(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"v1.2.3", Options{}, ""},
+		{"docs", Options{}, ""},
+		{"1.2.3", Options{}, `prefix "", expected "v"`},
+		{"release-1.2.3", Options{}, `prefix "release-", expected "v"`},
+		{"v1.2", Options{}, "2 components, expected 3"},
+		{"v1.2.3.4", Options{}, "4 components, expected 3"},
+		{"v1.02.3", Options{}, `leading zero in "02"`},
+		{"v1.2.3.4", Options{Components: 4}, ""},
+		{"api-v1.2.3", Options{Prefix: "api-v"}, ""},
+		{"services/api/v01.2", Options{TagNamespace: "services/api"}, `2 components, expected 3; leading zero in "01"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(lintTag(tt.name, tt.opts), "; "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := newRepo(t, "v1.0.0", "1.1.0", "latest")
+	got, err := runArgs(t, "--path", dir, "--lint")
+	if err == nil || err.Error() != "found 1 tag format violations" {
+		t.Errorf("error = %v", err)
+	}
+	if want := "1.1.0: prefix \"\", expected \"v\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCheckMonotonic(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"contiguous", []string{"v1.0.0", "v1.0.1", "v1.1.0", "v2.0.0"}, nil, "", false},
+		{"skipped minor", []string{"v1.0.0", "v1.2.0"}, nil, "v1.0.0 -> v1.2.0: invalid minor version: you cannot skip minor versions (latest: 0, input: 2)\n", true},
+		{"skipped patch", []string{"v1.0.0", "v1.0.2"}, nil, "v1.0.0 -> v1.0.2: expected v1.0.1\n", true},
+		{"lenient", []string{"v1.0.0", "v1.2.0"}, []string{"--strategy", "lenient"}, "", false},
+		{"prereleases ignored", []string{"v1.0.0", "v1.2.0-rc.1"}, nil, "", false},
+		{"prereleases", []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2", "v1.1.0"}, []string{"--prereleases"}, "", false},
+		{"skipping prerelease", []string{"v1.0.0", "v1.2.0-rc.1"}, []string{"--prereleases"}, "v1.0.0 -> v1.2.0-rc.1: invalid minor version: you cannot skip minor versions (latest: 0, input: 2)\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			got, err := runArgs(t, append([]string{"--path", dir, "--check-monotonic"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
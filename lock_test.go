@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockSerializes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "release.lock")
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := acquireLock(path)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock acquired while the first is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case second := <-acquired:
+		if second != nil {
+			second()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock not acquired after release")
+	}
+}
+
+func TestLockFileRun(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	lock := filepath.Join(t.TempDir(), "release.lock")
+	got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--lock-file", lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4" {
+		t.Errorf("got %q, want v1.2.4", got)
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Errorf("lock file not created: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetupLogger(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	tests := []struct {
+		format, level string
+		wantErr       bool
+		debug         bool
+	}{
+		{format: "text", level: "info"},
+		{format: "json", level: "debug", debug: true},
+		{format: "text", level: "WARN"},
+		{format: "xml", level: "info", wantErr: true},
+		{format: "text", level: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.level, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := setupLogger(&buf, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && slog.Default().Enabled(context.Background(), slog.LevelDebug) != tt.debug {
+				t.Errorf("debug enabled = %v, want %v", !tt.debug, tt.debug)
+			}
+		})
+	}
+}
+
+// decodeRecords returns the level and msg of every JSON log line
+func decodeRecords(t *testing.T, buf *bytes.Buffer) [][2]string {
+	t.Helper()
+	var records [][2]string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record struct {
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, [2]string{record.Level, record.Msg})
+	}
+	return records
+}
+
+func TestSetupLoggerJSON(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	tests := []struct {
+		level string
+		want  [][2]string
+	}{
+		{"debug", [][2]string{{"DEBUG", "debug"}, {"INFO", "info"}, {"WARN", "warn"}, {"ERROR", "error"}}},
+		{"info", [][2]string{{"INFO", "info"}, {"WARN", "warn"}, {"ERROR", "error"}}},
+		{"warn", [][2]string{{"WARN", "warn"}, {"ERROR", "error"}}},
+		{"error", [][2]string{{"ERROR", "error"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := setupLogger(&buf, "json", tt.level); err != nil {
+				t.Fatal(err)
+			}
+			slog.Debug("debug")
+			slog.Info("info")
+			slog.Warn("warn")
+			slog.Error("error")
+			if got := decodeRecords(t, &buf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLogsJSON(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	dir := newRepo(t)
+	commit(t, dir, "initial")
+	var buf bytes.Buffer
+	if err := setupLogger(&buf, "json", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := runArgs(t, "--path", dir, "--major", "0", "--minor", "1", "--warn-default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v0.1.0" {
+		t.Errorf("stdout = %q, want only the version", got)
+	}
+	// The debug records of the run are dropped at the warn level
+	want := [][2]string{{"WARN", "no matching semver tags found; starting from v0.0.0"}}
+	if records := decodeRecords(t, &buf); !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %v, want %v", records, want)
+	}
+}
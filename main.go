@@ -1,154 +1,1337 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// SemVer represents a semantic versioning tag
-type SemVer struct {
-	Major int
-	Minor int
-	Patch int
+// errNoTags stops a --no-default-start run that found no matching tags
+var errNoTags = errors.New("no matching semver tags found")
+
+// exitDowngrade is the exit code for downgrade attempts with --fail-on-downgrade-attempt
+const exitDowngrade = 3
+
+// Options holds every setting of a run, populated from flags by main
+type Options struct {
+	Path                   string
+	Major                  int
+	Minor                  int
+	Bump                   string
+	Edition                string
+	MaxTags                int
+	MaxPatch               int
+	TagIgnore              string
+	TagContains            string
+	TagContainsIgnoreCase  bool
+	AssertNext             string
+	MinorOnly              bool
+	FromBranch             bool
+	ValidateOnly           bool
+	Describe               bool
+	DevVersion             bool
+	LockFile               string
+	GitHubOutput           bool
+	UpdateFile             string
+	UpdatePattern          string
+	FromNth                int
+	BranchAware            bool
+	MainBranch             string
+	TagNamespace           string
+	StrictlyIncreasing     bool
+	ExportTags             string
+	ExportFormat           string
+	Select                 string
+	LatestPerMinor         bool
+	Target                 string
+	WarnDefault            bool
+	CalVer                 bool
+	VerifySignature        bool
+	CompareURLBase         string
+	RequireClean           bool
+	Strategy               string
+	PreBumpScript          string
+	GitHubRepo             string
+	GitHubToken            string
+	IncludeSubmodules      bool
+	EpochAware             bool
+	Format                 string
+	Taggers                []string
+	BumpLog                string
+	Components             int
+	TransitionLabel        bool
+	Prefix                 string
+	PrefixMap              string
+	PatchFromCommits       bool
+	Lint                   bool
+	IntWidth               int
+	Watch                  bool
+	WatchInterval          time.Duration
+	BaseRef                string
+	HeadRef                string
+	ExcludeHeadTag         bool
+	TestRegex              string
+	FormatJSONFile         string
+	WriteNotes             bool
+	FailOnDowngradeAttempt bool
+	KeepSuffix             bool
+	Channel                string
+	LatestBy               string
+	EnvFile                string
+	ReachableOnly          bool
+	OutputNewline          bool
+	Satisfies              string
+	SatisfiesVersion       string
+	NextPrerelease         string
+	Manifest               string
+	NoDefaultStart         bool
+	NoDefaultStartCode     int
+	SyncFrom               string
+	CheckMonotonic         bool
+	Serve                  string
+	StripPrefix            []string
+	Reach                  string
+	PrefixCase             string
+	AllowVersions          []string
+	SkipPatchZero          bool
+	Backend                string
+	PrintLatestRaw         bool
+	ConfirmMajor           bool
+	IMeanIt                bool
+	YearScoped             bool
+	UnderscoreBuild        bool
+	KeepBuild              bool
+	TagsFile               string
+	MajorNotBefore         string
+	Diff                   string
+	MaxAllowedMajor        int
+	MaxAllowedMinor        int
+	BumpOptions            bool
+	MinInterval            time.Duration
+	StripV                 bool
+	BumpTrailer            string
+	ListJSON               bool
+	Propose                bool
+	Commit                 string
+	ZeroVer                bool
+	NoGit                  bool
+	LogFormat              string
+	LogLevel               string
+	CreateTag              bool
+	ResolveConflicts       int
+	Prereleases            bool
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
 }
 
-func (v SemVer) String() string {
-	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// newFlagSet binds every command-line flag to opts
+func newFlagSet(opts *Options) *flag.FlagSet {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&opts.Path, "path", "", "Path to the Git repository")
+	fs.IntVar(&opts.Major, "major", -1, "Major version number")
+	fs.IntVar(&opts.MaxAllowedMajor, "max-allowed-major", 10000, "Reject --major inputs above this bound as typos (0 disables)")
+	fs.IntVar(&opts.MaxAllowedMinor, "max-allowed-minor", 10000, "Reject --minor inputs above this bound as typos (0 disables)")
+	fs.IntVar(&opts.Minor, "minor", -1, "Minor version number (defaults to the latest minor when --major is the latest major)")
+	fs.StringVar(&opts.Bump, "bump", "", "Bump the latest tag by patch, minor or major instead of giving --major/--minor")
+	fs.StringVar(&opts.Target, "target", "", "Major and minor as a single value such as 1.2 or v1.2, instead of --major/--minor")
+	fs.BoolVar(&opts.YearScoped, "year-scoped", false, "Use the current year as the major, bumping within it or starting at <year>.0.0")
+	fs.BoolVar(&opts.CalVer, "calver", false, "Compute a YYYY.MM.SEQ version for the current month instead of using --major/--minor")
+	fs.BoolVar(&opts.Describe, "describe", false, "Report the nearest tag, commit distance and short SHA using git describe")
+	fs.BoolVar(&opts.DevVersion, "dev-version", false, "With --describe, print a dev version such as v1.2.4-dev.5")
+	fs.BoolVar(&opts.ListJSON, "list-json", false, "Print every parsed semver tag as a JSON array, highest first, instead of computing a version")
+	fs.StringVar(&opts.ExportTags, "export-tags", "", "Write every parsed semver tag to this file instead of computing a version")
+	fs.StringVar(&opts.ExportFormat, "export-format", "json", "Format of --export-tags: json or yaml")
+	fs.StringVar(&opts.BaseRef, "base-ref", "", "Compare the latest version reachable from this ref with the one from --head-ref")
+	fs.StringVar(&opts.HeadRef, "head-ref", "", "Ref whose latest version must exceed the one of --base-ref")
+	fs.BoolVar(&opts.Prereleases, "prereleases", false, "Read prerelease tags such as v1.3.0-rc.1, ordered by SemVer precedence; otherwise they are ignored")
+	fs.StringVar(&opts.NextPrerelease, "next-prerelease", "", "Append <id>.N, numbered after the existing <id>.N tags of the computed version, e.g. rc")
+	fs.StringVar(&opts.Channel, "channel", "", "Release channel: stable (clean version), beta (-beta.N) or nightly (-nightly.<date>)")
+	fs.BoolVar(&opts.UnderscoreBuild, "underscore-build", false, "Accept tags with CI build metadata after an underscore, such as v1.2.3_build456, ignoring it for ordering")
+	fs.BoolVar(&opts.KeepBuild, "keep-build", false, "Append the build metadata of the latest tag to the next version")
+	fs.BoolVar(&opts.KeepSuffix, "keep-suffix", false, "Accept tags with an opaque _suffix, such as v1.2.3_linux_amd64, and carry it to the next version")
+	fs.StringVar(&opts.SyncFrom, "sync-from", "", "Use the latest tag of the repository at this path as the baseline, keeping both in lockstep")
+	fs.BoolVar(&opts.ReachableOnly, "reachable-only", false, "Ignore tags whose commit is not an ancestor of HEAD")
+	fs.BoolVar(&opts.ExcludeHeadTag, "exclude-head-tag", false, "Ignore tags pointing at HEAD, so a re-run bumps from the previous release")
+	fs.StringVar(&opts.TestRegex, "test-regex", "", "Print which tags a proposed pattern matches and the version each would parse to")
+	fs.StringVar(&opts.Reach, "reach", "", "Print the bumps --strategy needs to get from the latest tag to this version, e.g. v2.0.0")
+	fs.BoolVar(&opts.BumpOptions, "options", false, "Print the next patch, minor and major versions --strategy allows from the latest tag as a JSON array")
+	fs.BoolVar(&opts.CheckMonotonic, "check-monotonic", false, "Report consecutive tags that skip versions under --strategy and fail if there are any")
+	fs.BoolVar(&opts.Lint, "lint", false, "Report version-like tags that violate the configured tag format and fail if there are any")
+	fs.BoolVar(&opts.LatestPerMinor, "latest-per-minor", false, "Print the highest patch tag of every major.minor line instead of computing a version")
+	fs.StringVar(&opts.Serve, "serve", "", "Listen on this Unix socket and answer \"<path> <major> [<minor>]\" lines with the next version")
+	fs.BoolVar(&opts.Propose, "propose", false, "Print the next version stamped with a token for --commit instead of the plain version")
+	fs.StringVar(&opts.Commit, "commit", "", "Tag HEAD with the version of this --propose token if HEAD and the tags are unchanged")
+	fs.BoolVar(&opts.Watch, "watch", false, "Keep running and print the next version whenever the tags change")
+	fs.DurationVar(&opts.WatchInterval, "watch-interval", 2*time.Second, "How often --watch polls the tags")
+	fs.StringVar(&opts.PrefixCase, "prefix-case", "preserve", "Case of the rendered prefix: upper, lower or preserve")
+	fs.StringVar(&opts.Format, "format", "plain", "Comma-separated output formats: plain, shell (export statements), int (sortable integer) or json")
+	fs.StringVar(&opts.FormatJSONFile, "format-json-file", "", "Write the json format to this file instead of stdout")
+	fs.IntVar(&opts.IntWidth, "int-width", 3, "Decimal digits per minor and patch component for --format int")
+	fs.BoolVar(&opts.PrintLatestRaw, "print-latest-raw", false, "Print the exact name of the tag used as the latest version instead of the next version")
+	fs.BoolVar(&opts.TransitionLabel, "transition-label", false, "Print a label such as \"Minor feature release\" instead of the version")
+	fs.BoolVar(&opts.MinorOnly, "minor-only", false, "Print only v<major>.<minor> of the computed version")
+	fs.BoolVar(&opts.StripV, "strip-v", false, "Remove a single leading v from the printed version, whatever the tag prefix")
+	fs.BoolVar(&opts.FromBranch, "from-branch", false, "Read major and minor from the current release/<major>.<minor> branch")
+	fs.StringVar(&opts.Edition, "edition", "", "Only consider tags of this edition series, e.g. ce for v1.2.3-ce")
+	fs.StringVar(&opts.Satisfies, "satisfies", "", "Fail unless the version is within this range, e.g. '>=1.2.0 <2.0.0'")
+	fs.StringVar(&opts.SatisfiesVersion, "satisfies-version", "", "Check this version against --satisfies instead of computing one")
+	fs.StringVar(&opts.AssertNext, "assert-next", "", "Fail unless the computed version equals this version")
+	fs.IntVar(&opts.MaxTags, "max-tags", 0, "Only parse the N highest tags by git's version sort (0 parses all)")
+	fs.IntVar(&opts.Components, "components", 3, "Number of numeric version components: 2, 3 or 4")
+	fs.StringVar(&opts.Strategy, "strategy", "strict", "Increment rules: strict (no skipped versions) or lenient (skips allowed)")
+	fs.BoolVar(&opts.SkipPatchZero, "skip-patch-zero", false, "Start new minor and major lines at patch 1 instead of 0")
+	fs.BoolVar(&opts.ZeroVer, "zerover", false, "With --bump or --bump-trailer, let major bumps of 0.x versions bump the minor instead")
+	fs.StringVar(&opts.BumpTrailer, "bump-trailer", "", "Read the bump (patch, minor or major) from this trailer of the HEAD commit, e.g. Version-Bump; overrides --major/--minor")
+	fs.BoolVar(&opts.PatchFromCommits, "patch-from-commits", false, "Set the patch to the number of commits since the first tag of the minor line")
+	fs.IntVar(&opts.MaxPatch, "max-patch", 0, "Refuse patch bumps beyond this patch number (0 disables)")
+	fs.IntVar(&opts.FromNth, "from-nth", 0, "Use the Nth highest tag (0-based) as the baseline instead of the latest")
+	fs.StringVar(&opts.LatestBy, "latest-by", "semver", "How to pick the latest tag: semver (highest version) or topology (most recent tag reachable from HEAD)")
+	fs.StringVar(&opts.Select, "select", "latest", "Baseline within the selected major.minor line: latest or earliest patch")
+	fs.StringVar(&opts.Manifest, "manifest", "", "Compute every repository listed in this file, one \"<path> <major>.<minor>\" per line or a JSON array")
+	fs.StringVar(&opts.Diff, "diff", "", "Check every repository of a manifest with expected versions and report those that differ")
+	fs.StringVar(&opts.PrefixMap, "prefix-map", "", "Compute every service of a monorepo from name=prefix pairs, e.g. api=api-v,web=web-")
+	fs.BoolVar(&opts.IncludeSubmodules, "include-submodules", false, "Compute the next version of every submodule of --path")
+	fs.StringVar(&opts.Backend, "backend", "git", "How tags are read: git (the git binary) or go-git (in-process, needs a build with -tags gogit)")
+	fs.BoolVar(&opts.NoGit, "no-git", false, "Never run git; tags must come from --tags-file or --github-repo")
+	fs.StringVar(&opts.TagsFile, "tags-file", "", "Read newline-separated tag names from this file instead of git")
+	fs.StringVar(&opts.GitHubRepo, "github-repo", "", "Read tags of owner/name from the GitHub API instead of a local clone")
+	fs.StringVar(&opts.GitHubToken, "github-token", "", "Token for --github-repo requests")
+	fs.BoolVar(&opts.EpochAware, "epoch-aware", false, "Parse epoch-prefixed tags such as 1!v2.0.0; the epoch dominates ordering")
+	fs.StringVar(&opts.TagNamespace, "tag-namespace", "", "Only consider tags under refs/tags/<namespace>/, with the namespace stripped before parsing")
+	fs.Var((*stringList)(&opts.AllowVersions), "allow-version", "Accept this exact next version even if it skips versions, e.g. v3.0.0 (repeatable)")
+	fs.Var((*stringList)(&opts.StripPrefix), "strip-prefix", "Treat tags starting with this prefix as using the canonical v prefix, e.g. release- (repeatable)")
+	fs.Var((*stringList)(&opts.Taggers), "tagger", "Only consider annotated tags created by this tagger name (repeatable)")
+	fs.StringVar(&opts.TagIgnore, "tag-ignore", "", "Ignore tags matching this regular expression")
+	fs.StringVar(&opts.TagContains, "tag-contains", "", "Only consider tags containing this substring")
+	fs.BoolVar(&opts.TagContainsIgnoreCase, "tag-contains-ignore-case", false, "Match --tag-contains case-insensitively")
+	fs.BoolVar(&opts.BranchAware, "branch-aware", false, "Produce a prerelease version when not on the main branch")
+	fs.StringVar(&opts.MainBranch, "main-branch", "main", "Branch producing stable versions with --branch-aware")
+	fs.BoolVar(&opts.StrictlyIncreasing, "strictly-increasing", false, "Fail unless the computed version is greater than the latest tag")
+	fs.BoolVar(&opts.NoDefaultStart, "no-default-start", false, "Print nothing instead of starting from v0.0.0 when no tags match")
+	fs.IntVar(&opts.NoDefaultStartCode, "no-default-start-code", 0, "Exit code used by --no-default-start when no tags match")
+	fs.BoolVar(&opts.WarnDefault, "warn-default", false, "Warn when no tags match and the v0.0.0 starting point is used")
+	fs.BoolVar(&opts.VerifySignature, "verify-signature", false, "Require the baseline tag to pass git tag -v")
+	fs.StringVar(&opts.CompareURLBase, "compare-url-base", "", "Print <base>/compare/<latest>...HEAD instead of the version")
+	fs.BoolVar(&opts.RequireClean, "require-clean", false, "Fail if the working tree has uncommitted changes")
+	fs.DurationVar(&opts.MinInterval, "min-interval", 0, "Refuse to compute a version until this long after the latest tag's commit, e.g. 24h")
+	fs.StringVar(&opts.MajorNotBefore, "major-not-before", "", "Refuse major bumps before this date (YYYY-MM-DD), e.g. a planned GA date")
+	fs.BoolVar(&opts.ConfirmMajor, "confirm-major", false, "Require --i-mean-it, or a y/N answer on a terminal, for major bumps")
+	fs.BoolVar(&opts.IMeanIt, "i-mean-it", false, "Confirm a major bump guarded by --confirm-major")
+	fs.StringVar(&opts.PreBumpScript, "pre-bump-script", "", "Shell command run with the proposed version as its last argument; a non-zero exit aborts")
+	fs.BoolVar(&opts.CreateTag, "create-tag", false, "Tag HEAD with the computed version")
+	fs.IntVar(&opts.ResolveConflicts, "resolve-conflicts", 0, "With --create-tag, re-read the tags and retry up to N times when the tag was created concurrently")
+	fs.BoolVar(&opts.ValidateOnly, "validate-only", false, "Run all validations without printing a version")
+	fs.StringVar(&opts.UpdateFile, "update-file", "", "After computing, write the new version into this file")
+	fs.StringVar(&opts.UpdatePattern, "update-pattern", "", "Regular expression locating the version in --update-file; its first group is replaced if present")
+	fs.BoolVar(&opts.OutputNewline, "output-newline", false, "End the printed version with a newline")
+	fs.StringVar(&opts.EnvFile, "env-file", "", "Write the SEMVER_* variables to this dotenv file")
+	fs.BoolVar(&opts.GitHubOutput, "github-output", false, "Append version, major, minor and patch to the GITHUB_OUTPUT file")
+	fs.BoolVar(&opts.FailOnDowngradeAttempt, "fail-on-downgrade-attempt", false, fmt.Sprintf("Exit with code %d instead of 1 when the inputs are lower than the latest version", exitDowngrade))
+	fs.BoolVar(&opts.WriteNotes, "write-notes", false, "With --create-tag or --commit, attach the bump rationale to the tagged commit as a git note under refs/notes/semver")
+	fs.StringVar(&opts.BumpLog, "bump-log", "", "Append a JSON line recording each computed bump to this file")
+	fs.StringVar(&opts.LockFile, "lock-file", "", "Hold an exclusive lock on this file while computing the next version")
+	fs.StringVar(&opts.LogFormat, "log-format", "text", "Log format: text or json")
+	fs.StringVar(&opts.LogLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	return fs
 }
 
 func main() {
+	var opts Options
+
 	// Parse command-line flags
-	path := flag.String("path", "", "Path to the Git repository")
-	major := flag.Int("major", -1, "Major version number")
-	minor := flag.Int("minor", -1, "Minor version number")
-	flag.Parse()
+	fs := newFlagSet(&opts)
+	fs.Parse(os.Args[1:])
 
-	// Validate inputs
-	if *path == "" || *major == -1 || *minor == -1 {
-		log.Fatal("All parameters (--path, --major, --minor) must be provided")
+	if err := setupLogger(os.Stderr, opts.LogFormat, opts.LogLevel); err != nil {
+		fatal(err.Error())
+	}
+
+	if err := run(opts, os.Stdout); err != nil {
+		if errors.Is(err, errNoTags) {
+			slog.Debug(err.Error())
+			os.Exit(opts.NoDefaultStartCode)
+		}
+		if opts.FailOnDowngradeAttempt && errors.Is(err, ErrDowngrade) {
+			slog.Error(err.Error())
+			os.Exit(exitDowngrade)
+		}
+		fatal(err.Error())
+	}
+}
+
+// modes returns the flags given that replace computing a next version
+func (opts Options) modes() []string {
+	var modes []string
+	if opts.Describe {
+		modes = append(modes, "--describe")
+	}
+	if opts.ExportTags != "" {
+		modes = append(modes, "--export-tags")
+	}
+	if opts.ListJSON {
+		modes = append(modes, "--list-json")
+	}
+	if opts.LatestPerMinor {
+		modes = append(modes, "--latest-per-minor")
+	}
+	if opts.Lint {
+		modes = append(modes, "--lint")
+	}
+	if opts.CheckMonotonic {
+		modes = append(modes, "--check-monotonic")
+	}
+	if opts.Reach != "" {
+		modes = append(modes, "--reach")
+	}
+	if opts.BumpOptions {
+		modes = append(modes, "--options")
+	}
+	if opts.BaseRef != "" || opts.HeadRef != "" {
+		modes = append(modes, "--base-ref")
+	}
+	if opts.TestRegex != "" {
+		modes = append(modes, "--test-regex")
+	}
+	return modes
+}
+
+// recordFlags returns the set flags that write the version somewhere besides
+// stdout
+func (opts Options) recordFlags() []string {
+	var flags []string
+	if opts.BumpLog != "" {
+		flags = append(flags, "--bump-log")
+	}
+	if opts.WriteNotes {
+		flags = append(flags, "--write-notes")
+	}
+	if opts.UpdateFile != "" {
+		flags = append(flags, "--update-file")
+	}
+	if opts.GitHubOutput {
+		flags = append(flags, "--github-output")
+	}
+	if opts.EnvFile != "" {
+		flags = append(flags, "--env-file")
+	}
+	if opts.FormatJSONFile != "" {
+		flags = append(flags, "--format-json-file")
+	}
+	return flags
+}
+
+// localRepoFlag returns the first set flag that runs git in the repository
+// at --path, which external tag sources do not need
+func (opts Options) localRepoFlag() string {
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{opts.RequireClean, "--require-clean"},
+		{opts.FromBranch, "--from-branch"},
+		{opts.LatestBy == "topology", "--latest-by topology"},
+		{opts.BumpTrailer != "", "--bump-trailer"},
+		{opts.VerifySignature, "--verify-signature"},
+		{opts.PatchFromCommits, "--patch-from-commits"},
+		{opts.BranchAware, "--branch-aware"},
+		{opts.MinInterval > 0, "--min-interval"},
+		{opts.WriteNotes, "--write-notes"},
+	}
+	for _, f := range flags {
+		if f.set {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// formats returns the requested output formats
+func (opts Options) formats() []string {
+	var formats []string
+	for _, format := range strings.Split(opts.Format, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		return []string{"plain"}
 	}
+	return formats
+}
+
+func (opts Options) hasFormat(format string) bool {
+	return slices.Contains(opts.formats(), format)
+}
 
-	if err := run(*path, *major, *minor); err != nil {
-		log.Fatal(err)
+// stdoutFormat returns the format printed to stdout: json unless it goes to
+// --format-json-file, otherwise the single other format, if any
+func (opts Options) stdoutFormat() string {
+	for _, format := range opts.formats() {
+		if format != "json" || opts.FormatJSONFile == "" {
+			return format
+		}
 	}
+	return ""
+}
+
+// readsPrereleases reports whether prerelease tags are parsed: on request,
+// or to number and release the prerelease tracks of --channel and
+// --next-prerelease
+func (opts Options) readsPrereleases() bool {
+	return opts.Prereleases || opts.Channel != "" || opts.NextPrerelease != ""
 }
 
-func run(path string, majorInput, minorInput int) error {
-	// Step 1: Check if the path exists
-	if err := checkIfPathExists(path); err != nil {
+// externalTags reports whether tags are read from outside a local repository
+func (opts Options) externalTags() bool {
+	return opts.GitHubRepo != "" || opts.TagsFile != ""
+}
+
+// multiRepo reports whether the run covers several repositories
+func (opts Options) multiRepo() bool {
+	return opts.IncludeSubmodules || opts.PrefixMap != "" || opts.Manifest != "" || opts.Diff != "" || strings.ContainsAny(opts.Path, "*?[")
+}
+
+func validateOptions(opts Options) error {
+	if modes := opts.modes(); len(modes) > 0 {
+		if len(modes) > 1 {
+			return fmt.Errorf("%s cannot be combined with %s", modes[0], modes[1])
+		}
+		if opts.MinorOnly {
+			return fmt.Errorf("--minor-only cannot be combined with %s", modes[0])
+		}
+		if opts.ValidateOnly {
+			return fmt.Errorf("--validate-only cannot be combined with %s", modes[0])
+		}
+	}
+	if opts.Bump != "" && opts.Bump != "patch" && opts.Bump != "minor" && opts.Bump != "major" {
+		return fmt.Errorf("invalid --bump %q: must be patch, minor or major", opts.Bump)
+	}
+	if opts.ZeroVer && opts.Bump == "" && opts.BumpTrailer == "" {
+		return errors.New("--zerover requires --bump or --bump-trailer")
+	}
+	var stdoutFormats []string
+	for _, format := range opts.formats() {
+		switch format {
+		case "plain", "shell", "int":
+			stdoutFormats = append(stdoutFormats, format)
+		case "json":
+			if opts.FormatJSONFile == "" {
+				stdoutFormats = append(stdoutFormats, format)
+			}
+		default:
+			return fmt.Errorf("invalid --format %q: must be plain, shell, int or json", format)
+		}
+	}
+	if len(stdoutFormats) > 1 {
+		return fmt.Errorf("--format %s and %s cannot both print to stdout; only json can go to --format-json-file", stdoutFormats[0], stdoutFormats[1])
+	}
+	if opts.FormatJSONFile != "" {
+		if !opts.hasFormat("json") {
+			return errors.New("--format-json-file requires --format json")
+		}
+		if opts.multiRepo() {
+			return errors.New("--format-json-file cannot be used with several repositories")
+		}
+	}
+	if opts.hasFormat("int") && (opts.IntWidth < 1 || opts.IntWidth > 6) {
+		return fmt.Errorf("invalid --int-width %d: must be between 1 and 6", opts.IntWidth)
+	}
+	var outputs []string
+	if opts.MinorOnly {
+		outputs = append(outputs, "--minor-only")
+	}
+	if opts.CompareURLBase != "" {
+		outputs = append(outputs, "--compare-url-base")
+	}
+	if format := opts.stdoutFormat(); format != "plain" && format != "" {
+		outputs = append(outputs, "--format "+format)
+	}
+	if opts.TransitionLabel {
+		outputs = append(outputs, "--transition-label")
+	}
+	if opts.PrintLatestRaw {
+		outputs = append(outputs, "--print-latest-raw")
+	}
+	if len(outputs) > 1 {
+		return fmt.Errorf("%s cannot be combined with %s", outputs[0], outputs[1])
+	}
+	if opts.Components != 0 && (opts.Components < 2 || opts.Components > 4) {
+		return fmt.Errorf("invalid --components %d: must be 2, 3 or 4", opts.Components)
+	}
+	if _, err := strategyByName(opts.Strategy); err != nil {
+		return err
+	}
+	if _, ok := backends[opts.Backend]; !ok && opts.Backend != "" {
+		if opts.Backend == "go-git" {
+			return errors.New("--backend go-git is not available: build with -tags gogit")
+		}
+		return fmt.Errorf("invalid --backend %q: must be git or go-git", opts.Backend)
+	}
+	if opts.UnderscoreBuild && opts.KeepSuffix {
+		return errors.New("--underscore-build cannot be combined with --keep-suffix")
+	}
+	if opts.KeepBuild && !opts.UnderscoreBuild {
+		return errors.New("--keep-build requires --underscore-build")
+	}
+	if opts.BumpTrailer != "" && (opts.CalVer || opts.YearScoped) {
+		return errors.New("--bump-trailer cannot be combined with --calver or --year-scoped")
+	}
+	if opts.MinInterval < 0 {
+		return fmt.Errorf("invalid --min-interval %s: must not be negative", opts.MinInterval)
+	}
+	if opts.MajorNotBefore != "" {
+		if _, err := time.Parse(time.DateOnly, opts.MajorNotBefore); err != nil {
+			return fmt.Errorf("invalid --major-not-before %q: must be YYYY-MM-DD", opts.MajorNotBefore)
+		}
+	}
+	switch opts.PrefixCase {
+	case "", "preserve", "upper", "lower":
+	default:
+		return fmt.Errorf("invalid --prefix-case %q: must be upper, lower or preserve", opts.PrefixCase)
+	}
+	switch opts.LatestBy {
+	case "", "semver":
+	case "topology":
+		if opts.FromNth != 0 || opts.GitHubRepo != "" {
+			return errors.New("--latest-by topology cannot be combined with --from-nth or --github-repo")
+		}
+	default:
+		return fmt.Errorf("invalid --latest-by %q: must be semver or topology", opts.LatestBy)
+	}
+	switch opts.Channel {
+	case "", "stable", "beta", "nightly":
+	default:
+		return fmt.Errorf("invalid --channel %q: must be stable, beta or nightly", opts.Channel)
+	}
+	var prereleases []string
+	if opts.Channel != "" {
+		prereleases = append(prereleases, "--channel")
+	}
+	if opts.BranchAware {
+		prereleases = append(prereleases, "--branch-aware")
+	}
+	if opts.NextPrerelease != "" {
+		if !identifierRegex.MatchString(opts.NextPrerelease) {
+			return fmt.Errorf("invalid --next-prerelease %q: must be a single identifier", opts.NextPrerelease)
+		}
+		prereleases = append(prereleases, "--next-prerelease")
+	}
+	if len(prereleases) > 1 {
+		return fmt.Errorf("%s cannot be combined with %s", prereleases[0], prereleases[1])
+	}
+	if opts.Select != "" && opts.Select != "latest" && opts.Select != "earliest" {
+		return fmt.Errorf("invalid --select %q: must be latest or earliest", opts.Select)
+	}
+	if err := (SemVer{Edition: opts.Edition}).Validate(); err != nil {
 		return err
 	}
+	if opts.ExportTags != "" && opts.ExportFormat != "json" && opts.ExportFormat != "yaml" {
+		return fmt.Errorf("invalid --export-format %q: must be json or yaml", opts.ExportFormat)
+	}
+	if opts.TagsFile != "" {
+		if opts.GitHubRepo != "" || len(opts.Taggers) > 0 || opts.ExcludeHeadTag || opts.ReachableOnly || opts.SyncFrom != "" {
+			return errors.New("--tags-file cannot be combined with --github-repo, --tagger, --exclude-head-tag, --reachable-only or --sync-from")
+		}
+	}
+	if opts.NoGit {
+		if !opts.externalTags() {
+			return errors.New("--no-git requires --tags-file or --github-repo")
+		}
+		if opts.Path != "" || opts.SyncFrom != "" || opts.IncludeSubmodules {
+			return errors.New("--no-git cannot be combined with --path, --sync-from or --include-submodules")
+		}
+		if name := opts.localRepoFlag(); name != "" {
+			return fmt.Errorf("%s needs git and cannot be combined with --no-git", name)
+		}
+	}
+	if opts.externalTags() && opts.Path == "" {
+		if name := opts.localRepoFlag(); name != "" {
+			return fmt.Errorf("%s requires a local --path", name)
+		}
+	}
+	if opts.GitHubRepo != "" && opts.TagNamespace != "" {
+		return errors.New("--github-repo cannot be combined with --tag-namespace")
+	}
+	if opts.GitHubRepo != "" && opts.ExcludeHeadTag {
+		return errors.New("--github-repo cannot be combined with --exclude-head-tag")
+	}
+	if opts.SyncFrom != "" && (opts.GitHubRepo != "" || opts.BranchAware || opts.PatchFromCommits) {
+		return errors.New("--sync-from cannot be combined with --github-repo, --branch-aware or --patch-from-commits")
+	}
+	if opts.GitHubRepo != "" && opts.ReachableOnly {
+		return errors.New("--github-repo cannot be combined with --reachable-only")
+	}
+	if opts.GitHubRepo != "" && len(opts.Taggers) > 0 {
+		return errors.New("--github-repo cannot be combined with --tagger")
+	}
+	if (opts.BaseRef == "") != (opts.HeadRef == "") {
+		return errors.New("--base-ref and --head-ref must be provided together")
+	}
+	if (opts.UpdateFile == "") != (opts.UpdatePattern == "") {
+		return errors.New("--update-file and --update-pattern must be provided together")
+	}
+	if opts.IncludeSubmodules && strings.ContainsAny(opts.Path, "*?[") {
+		return errors.New("--include-submodules cannot be used with a path pattern")
+	}
+	if opts.TagContains != "" && opts.Edition == "" && !opts.KeepSuffix {
+		// Otherwise the matched text of a tag like v1.2.3-prod is lost in the next version
+		return errors.New("--tag-contains requires --edition or --keep-suffix")
+	}
+	if opts.PrefixMap != "" {
+		if opts.IncludeSubmodules || strings.ContainsAny(opts.Path, "*?[") {
+			return errors.New("--prefix-map cannot be combined with several repositories")
+		}
+		if _, err := parsePrefixMap(opts.PrefixMap); err != nil {
+			return err
+		}
+	}
+	if opts.Watch {
+		if opts.multiRepo() || opts.externalTags() {
+			return errors.New("--watch requires a single local repository")
+		}
+		if opts.ValidateOnly || opts.UpdateFile != "" || opts.GitHubOutput {
+			return errors.New("--watch only prints versions and cannot be combined with --validate-only, --update-file or --github-output")
+		}
+		if opts.WatchInterval <= 0 {
+			return fmt.Errorf("invalid --watch-interval %s: must be positive", opts.WatchInterval)
+		}
+	}
+	if opts.ConfirmMajor || opts.PreBumpScript != "" {
+		if opts.multiRepo() || opts.Watch || opts.Propose {
+			return errors.New("--confirm-major and --pre-bump-script only guard a single bump and cannot be combined with several repositories, --watch or --propose")
+		}
+	}
+	if opts.Propose || opts.Commit != "" {
+		if opts.Propose && opts.Commit != "" {
+			return errors.New("--propose cannot be combined with --commit")
+		}
+		if opts.multiRepo() || opts.externalTags() || opts.Watch {
+			return errors.New("--propose and --commit require a single local repository")
+		}
+		if opts.CreateTag {
+			return errors.New("--create-tag cannot be combined with --propose or --commit, which tags on its own")
+		}
+	}
+	if opts.Propose {
+		// The token is all --propose prints; the --commit run formats and
+		// records the version it tags
+		flags := append(outputs, opts.recordFlags()...)
+		if opts.StripV {
+			flags = append(flags, "--strip-v")
+		}
+		if len(flags) > 0 {
+			return fmt.Errorf("--propose cannot be combined with %s", flags[0])
+		}
+	}
+	if opts.ResolveConflicts < 0 {
+		return fmt.Errorf("invalid --resolve-conflicts %d: must not be negative", opts.ResolveConflicts)
+	}
+	if opts.ResolveConflicts > 0 && !opts.CreateTag {
+		return errors.New("--resolve-conflicts requires --create-tag")
+	}
+	if opts.CreateTag {
+		if len(opts.modes()) > 0 || opts.multiRepo() || opts.externalTags() || opts.Watch || opts.Serve != "" {
+			return errors.New("--create-tag requires a single local repository and cannot be combined with --watch, --serve or other modes")
+		}
+	}
+	if opts.WriteNotes && !opts.CreateTag && opts.Commit == "" {
+		return errors.New("--write-notes requires --create-tag or --commit")
+	}
+	if opts.UpdateFile != "" && opts.multiRepo() {
+		return errors.New("--update-file cannot be used with several repositories")
+	}
+	if opts.NoDefaultStart && opts.multiRepo() {
+		return errors.New("--no-default-start cannot be used with several repositories")
+	}
+	if opts.EnvFile != "" && opts.multiRepo() {
+		return errors.New("--env-file cannot be used with several repositories")
+	}
+	if opts.GitHubOutput && opts.multiRepo() {
+		return errors.New("--github-output cannot be used with several repositories")
+	}
+	if opts.Satisfies != "" {
+		if _, err := parseRange(opts.Satisfies); err != nil {
+			return err
+		}
+	}
+	if opts.Serve != "" {
+		if opts.Path != "" || opts.Major != -1 || opts.Minor != -1 || len(opts.modes()) > 0 || opts.Watch {
+			return errors.New("--serve takes paths and versions from each request and cannot be combined with --path, version inputs, --watch or other modes")
+		}
+		if opts.ConfirmMajor || opts.PreBumpScript != "" {
+			// Requests are answered concurrently without a terminal
+			return errors.New("--serve cannot be combined with --confirm-major or --pre-bump-script")
+		}
+		return nil
+	}
+	if opts.SatisfiesVersion != "" {
+		if opts.Satisfies == "" {
+			return errors.New("--satisfies-version requires --satisfies")
+		}
+		if _, err := parseVersion(opts.SatisfiesVersion); err != nil {
+			return err
+		}
+		return nil
+	}
+	if len(opts.modes()) > 0 {
+		if opts.Path == "" {
+			return errors.New("--path must be provided")
+		}
+		return nil
+	}
 
-	// Step 2: Check if the path is a Git repository
-	if err := checkIfGitRepo(path); err != nil {
+	if opts.Manifest != "" && opts.Diff != "" {
+		return errors.New("--manifest cannot be combined with --diff")
+	}
+	if opts.Manifest != "" || opts.Diff != "" {
+		if opts.Path != "" || opts.Major != -1 || opts.Minor != -1 || opts.Target != "" || opts.FromBranch || opts.CalVer {
+			return errors.New("--manifest and --diff provide the paths and versions and cannot be combined with --path or version inputs")
+		}
+		return nil
+	}
+
+	// Major and minor come from exactly one source
+	var sources []string
+	if opts.Major != -1 || (opts.Minor != -1 && !opts.YearScoped) {
+		sources = append(sources, "--major/--minor")
+	}
+	if opts.Bump != "" {
+		sources = append(sources, "--bump")
+	}
+	if opts.YearScoped {
+		sources = append(sources, "--year-scoped")
+	}
+	if opts.Target != "" {
+		sources = append(sources, "--target")
+	}
+	if opts.FromBranch {
+		sources = append(sources, "--from-branch")
+	}
+	if opts.CalVer {
+		sources = append(sources, "--calver")
+	}
+	if len(sources) > 1 {
+		return fmt.Errorf("%s cannot be combined with %s", sources[0], sources[1])
+	}
+	majorInput, minorInput := opts.Major, opts.Minor
+	if opts.Target != "" {
+		var err error
+		if majorInput, minorInput, err = parseTarget(opts.Target); err != nil {
+			return err
+		}
+	}
+	if _, err := parseAllowedVersions(opts.AllowVersions); err != nil {
 		return err
 	}
+	if opts.MaxAllowedMajor > 0 && majorInput > opts.MaxAllowedMajor {
+		return fmt.Errorf("major %d is above --max-allowed-major %d; check the input for a typo", majorInput, opts.MaxAllowedMajor)
+	}
+	if opts.MaxAllowedMinor > 0 && minorInput > opts.MaxAllowedMinor {
+		return fmt.Errorf("minor %d is above --max-allowed-minor %d; check the input for a typo", minorInput, opts.MaxAllowedMinor)
+	}
+	if len(sources) == 1 && sources[0] != "--major/--minor" {
+		if opts.Path == "" && !opts.externalTags() {
+			return errors.New("--path must be provided")
+		}
+		return nil
+	}
+	if (opts.Path == "" && !opts.externalTags()) || (opts.Major == -1 && opts.Minor == -1 && opts.BumpTrailer == "") {
+		return errors.New("parameters --path and --major or --minor must be provided")
+	}
+	return nil
+}
 
-	// Step 3: Get the latest SemVer tag
-	tags, err := getSemverTags()
-	if err != nil {
+// run executes the configured mode, writing results to out; the CLI passes
+// os.Stdout while embedding programs can capture them
+func run(opts Options, out io.Writer) error {
+	// Validate inputs
+	if err := validateOptions(opts); err != nil {
 		return err
 	}
-	latestTag := tags[0]
 
-	// Step 4: Calculate the next version based on inputs
-	nextVersion, err := calculateNextVersion(latestTag, majorInput, minorInput)
+	if opts.Backend != "" {
+		backend = backends[opts.Backend]
+	}
+
+	if opts.SatisfiesVersion != "" {
+		return runSatisfies(opts)
+	}
+	if opts.Serve != "" {
+		return runServe(opts)
+	}
+	if opts.Describe {
+		return runDescribe(opts, out)
+	}
+	if opts.ExportTags != "" {
+		return runExportTags(opts)
+	}
+	if opts.ListJSON {
+		return runListJSON(opts, out)
+	}
+	if opts.LatestPerMinor {
+		return runLatestPerMinor(opts, out)
+	}
+	if opts.Lint {
+		return runLint(opts, out)
+	}
+	if opts.CheckMonotonic {
+		return runCheckMonotonic(opts, out)
+	}
+	if opts.Reach != "" {
+		return runReach(opts, out)
+	}
+	if opts.BumpOptions {
+		return runBumpOptions(opts, out)
+	}
+	if opts.BaseRef != "" {
+		return runCompareRefs(opts, out)
+	}
+	if opts.TestRegex != "" {
+		return runTestRegex(opts, out)
+	}
+
+	if opts.Watch {
+		return runWatch(opts, out)
+	}
+
+	if opts.LockFile != "" {
+		unlock, err := acquireLock(opts.LockFile)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if opts.Propose {
+		return runPropose(opts, out)
+	}
+	if opts.Commit != "" {
+		return runCommit(opts, out)
+	}
+	if opts.PrefixMap != "" {
+		return runPrefixMap(opts, out)
+	}
+	if opts.Manifest != "" {
+		return runManifest(opts, out)
+	}
+	if opts.Diff != "" {
+		return runDiff(opts, out)
+	}
+
+	if opts.IncludeSubmodules {
+		if err := checkIfGitRepo(opts.Path); err != nil {
+			return err
+		}
+		submodules, err := listSubmodules(opts.Path)
+		if err != nil {
+			return err
+		}
+		paths := make([]string, len(submodules))
+		for i, submodule := range submodules {
+			paths[i] = filepath.Join(opts.Path, submodule)
+		}
+		return runEach(paths, opts, out)
+	}
+
+	if !strings.ContainsAny(opts.Path, "*?[") {
+		latestTag, nextVersion, err := computeNextVersion(opts.Path, opts)
+		if err != nil {
+			return err
+		}
+		if opts.ValidateOnly {
+			return nil
+		}
+		if err := beforeBump(latestTag, nextVersion, opts); err != nil {
+			return err
+		}
+		if opts.CreateTag {
+			if latestTag, nextVersion, err = createNextTag(latestTag, nextVersion, opts); err != nil {
+				return err
+			}
+		}
+		return afterBump(latestTag, nextVersion, opts, out)
+	}
+
+	// The path is a glob: process every matching repository independently
+	matches, err := filepath.Glob(opts.Path)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid path pattern %s: %w", opts.Path, err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("path pattern %s did not match anything", opts.Path)
 	}
+	return runEach(matches, opts, out)
+}
 
-	fmt.Print(nextVersion)
+// afterBump records and prints a bump of the repository at --path once the
+// guards passed and any tag was created
+func afterBump(latestTag, nextVersion SemVer, opts Options, out io.Writer) error {
+	if opts.BumpLog != "" {
+		appendBumpLog(opts, opts.Path, latestTag, nextVersion)
+	}
+	if opts.WriteNotes {
+		writeBumpNote(opts, opts.Path, latestTag, nextVersion)
+	}
+	if opts.UpdateFile != "" {
+		if err := updateFile(opts.UpdateFile, opts.UpdatePattern, FormatTag(nextVersion, opts)); err != nil {
+			return err
+		}
+	}
+	if opts.GitHubOutput {
+		if err := writeGitHubOutput(nextVersion, opts); err != nil {
+			return err
+		}
+	}
+	if opts.EnvFile != "" {
+		if err := writeEnvFile(opts.EnvFile, nextVersion, opts); err != nil {
+			return err
+		}
+	}
+	if opts.FormatJSONFile != "" {
+		if err := os.WriteFile(opts.FormatJSONFile, append(versionJSON(latestTag, nextVersion, opts), '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.FormatJSONFile, err)
+		}
+	}
+	printResult(out, formatVersion(latestTag, nextVersion, opts), opts)
 	return nil
 }
 
-func checkIfPathExists(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("path %s does not exist", path)
+// runPrefixMap computes the next version of every service of a monorepo,
+// each identified by its own tag prefix, printing "<service> <version>" lines
+func runPrefixMap(opts Options, out io.Writer) error {
+	services, err := parsePrefixMap(opts.PrefixMap)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	var errs []error
+	for _, service := range services {
+		serviceOpts := opts
+		serviceOpts.Prefix = service[1]
+		latestTag, nextVersion, err := computeNextVersion(opts.Path, serviceOpts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", service[0], err))
+			continue
+		}
+		if !opts.ValidateOnly {
+			fmt.Fprintf(out, "%s %s\n", service[0], formatVersion(latestTag, nextVersion, serviceOpts))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parsePrefixMap reads "name=prefix,name=prefix" pairs in order
+func parsePrefixMap(value string) ([][2]string, error) {
+	var services [][2]string
+	for _, entry := range strings.Split(value, ",") {
+		name, prefix, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || prefix == "" {
+			return nil, fmt.Errorf("invalid --prefix-map entry %q: expected name=prefix", entry)
+		}
+		services = append(services, [2]string{name, prefix})
+	}
+	return services, nil
+}
+
+// runEach computes the next version of every repository independently,
+// printing one "<path> <version>" line per repository and collecting errors
+func runEach(paths []string, opts Options, out io.Writer) error {
+	var errs []error
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			slog.Warn("skipping match that is not a directory", "path", path)
+			continue
+		}
+		latestTag, nextVersion, err := computeNextVersion(path, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if opts.ValidateOnly {
+			continue
+		}
+		if opts.BumpLog != "" {
+			appendBumpLog(opts, path, latestTag, nextVersion)
+		}
+		fmt.Fprintf(out, "%s %s\n", path, formatVersion(latestTag, nextVersion, opts))
+	}
+	return errors.Join(errs...)
 }
 
-func checkIfGitRepo(path string) error {
-	if err := os.Chdir(path); err != nil {
-		return fmt.Errorf("failed to change directory to %s: %w", path, err)
+func runLatestPerMinor(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
 	}
 
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	output, err := cmd.CombinedOutput()
-	if err != nil || strings.TrimSpace(string(output)) != "true" {
-		return fmt.Errorf("path %s is not a Git repository", path)
+	tags, err := getSemverTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	// Tags are sorted highest first, so the first tag of each line is its latest
+	for i, tag := range tags {
+		if tag.Raw == "" {
+			continue
+		}
+		if i > 0 && tags[i-1].Major == tag.Major && tags[i-1].Minor == tag.Minor {
+			continue
+		}
+		fmt.Fprintln(out, FormatTag(tag, opts))
 	}
 	return nil
 }
 
-func getSemverTags() ([]SemVer, error) {
-	cmd := exec.Command("git", "tag", "--list")
-	output, err := cmd.CombinedOutput()
+// computeNextVersion returns the baseline tag and the next version for the repository at path
+func computeNextVersion(path string, opts Options) (SemVer, SemVer, error) {
+	majorInput, minorInput := opts.Major, opts.Minor
+	if opts.Target != "" {
+		majorInput, minorInput, _ = parseTarget(opts.Target)
+	}
+
+	// Steps 1 and 2 are skipped when tags come from GitHub or a file without a local clone
+	if !opts.externalTags() || path != "" {
+		// Step 1: Check if the path exists
+		if err := checkIfPathExists(path); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+
+		// Step 2: Check if the path is a Git repository
+		if err := checkIfGitRepo(path); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	if opts.RequireClean {
+		if err := checkCleanWorktree(path); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	if opts.FromBranch {
+		branch, err := getCurrentBranch(path, "--from-branch")
+		if err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+		majorInput, minorInput, err = parseReleaseBranch(branch)
+		if err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+		slog.Debug("read version from branch", "branch", branch, "major", majorInput, "minor", minorInput)
+	}
+
+	// Step 3: Get the latest SemVer tag, from another repository with --sync-from
+	tagPath := path
+	if opts.SyncFrom != "" {
+		if err := checkIfPathExists(opts.SyncFrom); err != nil {
+			return SemVer{}, SemVer{}, fmt.Errorf("--sync-from: %w", err)
+		}
+		if err := checkIfGitRepo(opts.SyncFrom); err != nil {
+			return SemVer{}, SemVer{}, fmt.Errorf("--sync-from: %w", err)
+		}
+		tagPath = opts.SyncFrom
+	}
+	tags, err := getSemverTags(tagPath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tags: %w", err)
+		return SemVer{}, SemVer{}, err
+	}
+	if opts.NoDefaultStart && tags[0].Raw == "" {
+		return SemVer{}, SemVer{}, errNoTags
+	}
+	if opts.WarnDefault && tags[0].Raw == "" {
+		slog.Warn("no matching semver tags found; starting from " + tags[0].String())
+	}
+	if opts.FromNth < 0 || opts.FromNth >= len(tags) {
+		return SemVer{}, SemVer{}, fmt.Errorf("--from-nth %d is out of range: found %d tags", opts.FromNth, len(tags))
 	}
+	latestTag := tags[opts.FromNth]
+	if opts.LatestBy == "topology" && tags[0].Raw != "" {
+		if latestTag, err = latestTagByTopology(tagPath, tags); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+	if opts.Select == "earliest" {
+		// Use the lowest patch of the selected major.minor line instead
+		latestTag = earliestInLine(tags, latestTag)
+	}
+	// --year-scoped bumps within the current year's major, which starts at <year>.0.0
+	yearStart := false
+	if opts.YearScoped {
+		majorInput, yearStart = now().Year(), true
+		for _, tag := range tags {
+			if tag.Major == majorInput && tag.Raw != "" {
+				latestTag, yearStart = tag, false
+				break
+			}
+		}
+	}
+	slog.Debug("selected latest tag", "tag", latestTag.String(), "candidates", len(tags))
 
-	semverRegex := regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)$`)
-	tags := strings.Split(string(output), "\n")
-	var semverTags []SemVer
+	if opts.Bump != "" {
+		majorInput, minorInput = bumpInputs(latestTag, opts.Bump, opts.ZeroVer)
+	}
 
-	for _, tag := range tags {
-		tag = strings.TrimSpace(tag)
-		if tag == "" {
-			continue
+	// A bump trailer on HEAD overrides the other inputs
+	if opts.BumpTrailer != "" {
+		bump, err := readBumpTrailer(path, opts.BumpTrailer)
+		if err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+		switch kind := strings.ToLower(bump); kind {
+		case "patch", "minor", "major":
+			majorInput, minorInput = bumpInputs(latestTag, kind, opts.ZeroVer)
+		case "":
+			if majorInput == -1 && minorInput == -1 {
+				return SemVer{}, SemVer{}, fmt.Errorf("HEAD has no %s trailer and neither --major nor --minor is given", opts.BumpTrailer)
+			}
+		default:
+			return SemVer{}, SemVer{}, fmt.Errorf("invalid %s trailer %q: must be patch, minor or major", opts.BumpTrailer, bump)
+		}
+		slog.Debug("read bump from trailer", "trailer", opts.BumpTrailer, "bump", bump)
+	}
+
+	// The v0.0.0 starting point has no tag to verify
+	if opts.VerifySignature && latestTag.Raw != "" {
+		if err := verifyTagSignature(tagPath, latestTag.Raw); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	// Without --major, a minor bump stays within the latest major
+	if majorInput == -1 && !opts.CalVer {
+		majorInput = latestTag.Major
+		slog.Debug("inferred major from latest tag", "major", majorInput)
+	}
+
+	// Without --minor, a patch bump within the latest major keeps its minor
+	if minorInput == -1 && !opts.CalVer && !yearStart {
+		if majorInput != latestTag.Major {
+			return SemVer{}, SemVer{}, fmt.Errorf("--minor is required unless --major equals the latest major version (%d)", latestTag.Major)
+		}
+		minorInput = latestTag.Minor
+	}
+
+	// Step 4: Calculate the next version based on inputs
+	var nextVersion SemVer
+	if opts.CalVer {
+		nextVersion, err = calculateCalVer(latestTag, now())
+	} else if yearStart {
+		nextVersion = Zero()
+		nextVersion.Major, nextVersion.Components = majorInput, opts.Components
+	} else {
+		var strategy IncrementStrategy
+		// --allow-version values were checked by validateOptions
+		allowed, _ := parseAllowedVersions(opts.AllowVersions)
+		if strategy, err = strategyByName(opts.Strategy); err == nil {
+			nextVersion, err = calculateNextVersion(strategy, latestTag, majorInput, minorInput, allowed)
+		}
+		if err == nil && opts.PatchFromCommits && BumpKind(latestTag, nextVersion) == "patch" {
+			nextVersion.Patch, err = patchFromCommits(path, earliestInLine(tags, latestTag), latestTag)
 		}
-		if matches := semverRegex.FindStringSubmatch(tag); matches != nil {
-			major, _ := strconv.Atoi(matches[1])
-			minor, _ := strconv.Atoi(matches[2])
-			patch, _ := strconv.Atoi(matches[3])
-			semverTags = append(semverTags, SemVer{Major: major, Minor: minor, Patch: patch})
+		if kind := BumpKind(latestTag, nextVersion); err == nil && opts.SkipPatchZero && (kind == "minor" || kind == "major") {
+			// Never publish a .0 patch: a new line starts at .1
+			nextVersion.Patch = 1
 		}
+		nextVersion = applyComponents(latestTag, nextVersion, opts.Components)
+	}
+	if err != nil {
+		return SemVer{}, SemVer{}, err
+	}
+	nextVersion.Prefix = opts.Prefix
+	nextVersion.Edition = opts.Edition
+	nextVersion.Epoch = latestTag.Epoch
+	nextVersion.Suffix = latestTag.Suffix
+	if opts.KeepBuild {
+		nextVersion.Build = latestTag.Build
 	}
 
-	if len(semverTags) == 0 {
-		// No existing semver tags found; start from v0.0.0
-		semverTags = append(semverTags, SemVer{Major: 0, Minor: 0, Patch: 0})
+	// Bumping an older baseline can land on a version that was released since
+	if baseline := olderBaseline(opts); baseline != "" {
+		for _, tag := range tags {
+			if tag.Raw != "" && tag.Equal(nextVersion) {
+				return SemVer{}, SemVer{}, fmt.Errorf("computed version %s from %s already exists as tag %s", nextVersion, baseline, tag.Raw)
+			}
+		}
 	}
 
-	sort.Slice(semverTags, func(i, j int) bool {
-		if semverTags[i].Major != semverTags[j].Major {
-			return semverTags[i].Major > semverTags[j].Major
+	if opts.BranchAware {
+		branch, err := getCurrentBranch(path, "--branch-aware")
+		if err != nil {
+			return SemVer{}, SemVer{}, err
 		}
-		if semverTags[i].Minor != semverTags[j].Minor {
-			return semverTags[i].Minor > semverTags[j].Minor
+		if branch != opts.MainBranch {
+			commits, err := countCommitsSince(path, latestTag.Raw)
+			if err != nil {
+				return SemVer{}, SemVer{}, err
+			}
+			nextVersion.Prerelease = fmt.Sprintf("%s.%d", sanitizeIdentifier(branch), commits)
 		}
-		return semverTags[i].Patch > semverTags[j].Patch
-	})
+	}
 
-	return semverTags, nil
+	if opts.Channel != "" {
+		if nextVersion, err = applyChannel(path, nextVersion, opts); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+	if opts.NextPrerelease != "" {
+		if nextVersion, err = nextPrerelease(path, nextVersion, opts.NextPrerelease, opts); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	if err := nextVersion.Validate(); err != nil {
+		return SemVer{}, SemVer{}, err
+	}
+
+	if opts.StrictlyIncreasing && Compare(nextVersion, tags[0]) <= 0 {
+		return SemVer{}, SemVer{}, fmt.Errorf("computed version %s does not exceed the latest version %s", nextVersion, tags[0])
+	}
+
+	if opts.MaxPatch > 0 && nextVersion.Patch > opts.MaxPatch {
+		return SemVer{}, SemVer{}, fmt.Errorf("patch %d would exceed the maximum of %d; bump the minor version instead (--minor %d)", nextVersion.Patch, opts.MaxPatch, latestTag.Minor+1)
+	}
+
+	if opts.hasFormat("int") {
+		if _, err := encodeVersionInt(nextVersion, opts.IntWidth); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	if opts.Satisfies != "" {
+		if err := checkSatisfies(nextVersion, opts); err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+	}
+
+	if nextTag := FormatTag(nextVersion, opts); opts.AssertNext != "" && opts.AssertNext != nextTag {
+		return SemVer{}, SemVer{}, fmt.Errorf("next version does not match --assert-next: - %s (asserted) + %s (computed)", opts.AssertNext, nextTag)
+	}
+
+	if opts.MajorNotBefore != "" && BumpKind(latestTag, nextVersion) == "major" {
+		// Validated by validateOptions
+		notBefore, _ := time.ParseInLocation(time.DateOnly, opts.MajorNotBefore, time.Local)
+		if now().Before(notBefore) {
+			return SemVer{}, SemVer{}, fmt.Errorf("major bump to %s is not allowed before %s", FormatTag(nextVersion, opts), opts.MajorNotBefore)
+		}
+	}
+
+	if opts.MinInterval > 0 && latestTag.Raw != "" {
+		released, err := tagCommitTime(tagPath, latestTag.Raw)
+		if err != nil {
+			return SemVer{}, SemVer{}, err
+		}
+		if elapsed := now().Sub(released); elapsed < opts.MinInterval {
+			return SemVer{}, SemVer{}, fmt.Errorf("latest tag %s is only %s old; --min-interval requires %s between releases", latestTag.Raw, elapsed.Round(time.Second), opts.MinInterval)
+		}
+	}
+
+	return latestTag, nextVersion, nil
 }
 
-func calculateNextVersion(latestTag SemVer, majorInput, minorInput int) (SemVer, error) {
-	if majorInput < latestTag.Major {
-		return SemVer{}, fmt.Errorf("invalid major version: input major (%d) cannot be less than the latest major version (%d)", majorInput, latestTag.Major)
+// sanitizeIdentifier replaces characters not allowed in prerelease identifiers
+// and drops the leading zeros numeric identifiers must not have
+func sanitizeIdentifier(s string) string {
+	s = regexp.MustCompile(`[^0-9A-Za-z-]`).ReplaceAllString(s, "-")
+	if s != "" && strings.Trim(s, "0123456789") == "" {
+		if s = strings.TrimLeft(s, "0"); s == "" {
+			s = "0"
+		}
 	}
-	if majorInput == latestTag.Major {
-		if minorInput < latestTag.Minor {
-			return SemVer{}, fmt.Errorf("invalid minor version: input minor (%d) cannot be less than the latest minor version (%d)", minorInput, latestTag.Minor)
+	return s
+}
+
+// olderBaseline names the option bumping from a tag other than the latest, if any
+func olderBaseline(opts Options) string {
+	switch {
+	case opts.FromNth > 0:
+		return fmt.Sprintf("--from-nth %d", opts.FromNth)
+	case opts.Select == "earliest":
+		return "--select earliest"
+	}
+	return ""
+}
+
+// earliestInLine returns the lowest tag sharing the major.minor of v
+func earliestInLine(tags []SemVer, v SemVer) SemVer {
+	earliest := v
+	for _, tag := range tags {
+		if tag.Major == v.Major && tag.Minor == v.Minor && Compare(tag, earliest) < 0 {
+			earliest = tag
 		}
-		if minorInput == latestTag.Minor {
-			return SemVer{Major: majorInput, Minor: minorInput, Patch: latestTag.Patch + 1}, nil
-		} else if minorInput == latestTag.Minor+1 {
-			return SemVer{Major: majorInput, Minor: minorInput, Patch: 0}, nil
+	}
+	return earliest
+}
+
+// patchFromCommits uses the number of commits since the first tag of the
+// minor line as the patch, which must move past the latest patch
+func patchFromCommits(path string, firstTag, latestTag SemVer) (int, error) {
+	commits, err := countCommitsSince(path, firstTag.Raw)
+	if err != nil {
+		return 0, err
+	}
+	if commits <= latestTag.Patch {
+		return 0, fmt.Errorf("%d commits since %s do not exceed the latest patch %d", commits, firstTag, latestTag.Patch)
+	}
+	return commits, nil
+}
+
+// parseAllowedVersions reads the --allow-version values, which must be the
+// first version of a major or minor line
+func parseAllowedVersions(values []string) ([]SemVer, error) {
+	var allowed []SemVer
+	for _, value := range values {
+		v, err := parseVersion(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --allow-version: %w", err)
+		}
+		if v.Patch != 0 || v.Prerelease != "" {
+			return nil, fmt.Errorf("invalid --allow-version %s: only x.y.0 versions can be skipped to", value)
 		}
-		return SemVer{}, fmt.Errorf("invalid minor version: you cannot skip minor versions (latest: %d, input: %d)", latestTag.Minor, minorInput)
+		allowed = append(allowed, v)
 	}
+	return allowed, nil
+}
 
-	if majorInput == latestTag.Major+1 && minorInput == 0 {
-		return SemVer{Major: majorInput, Minor: minorInput, Patch: 0}, nil
+// parseTarget reads major and minor from a target such as 1.2, v1.2 or 1.2.3;
+// a patch component is accepted but ignored
+func parseTarget(target string) (int, int, error) {
+	matches := regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.\d+)?$`).FindStringSubmatch(target)
+	if matches == nil {
+		return 0, 0, fmt.Errorf("invalid --target %q: expected <major>.<minor>", target)
 	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return major, minor, nil
+}
 
-	return SemVer{}, fmt.Errorf("invalid version: skipping versions is not allowed (latest: %s, input: v%d.%d.x)", latestTag, majorInput, minorInput)
+func checkIfPathExists(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("path %s does not exist", path)
+	}
+	if err == nil && !info.IsDir() {
+		return fmt.Errorf("path %s is not a directory", path)
+	}
+	return nil
 }
@@ -0,0 +1,927 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseArgs returns the options a command line would produce
+func parseArgs(t *testing.T, args ...string) Options {
+	t.Helper()
+	var opts Options
+	fs := newFlagSet(&opts)
+	fs.Init("semver-calculator", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parse %v: %v", args, err)
+	}
+	return opts
+}
+
+// runArgs runs the tool with a command line and returns what it printed
+func runArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(parseArgs(t, args...), &out)
+	return out.String(), err
+}
+
+// mainArgsEnv carries the command line of a test binary re-executed by exitCode
+const mainArgsEnv = "SEMVER_CALCULATOR_TEST_ARGS"
+
+// exitCode runs main with a command line in a new process of the test binary,
+// which must call runMainIfRequested first, and returns its exit code
+func exitCode(t *testing.T, args ...string) int {
+	t.Helper()
+	test, _, _ := strings.Cut(t.Name(), "/")
+	cmd := exec.Command(os.Args[0], "-test.run=^"+test+"$")
+	cmd.Env = append(os.Environ(), mainArgsEnv+"="+strings.Join(args, "\x1f"))
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0
+}
+
+// runMainIfRequested runs main instead of the test in processes started by exitCode
+func runMainIfRequested() {
+	if args, ok := os.LookupEnv(mainArgsEnv); ok {
+		os.Args = append([]string{"semver-calculator"}, strings.Split(args, "\x1f")...)
+		main()
+		os.Exit(0)
+	}
+}
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newRepo creates a repository on main with one commit per tag, tagged in order
+func newRepo(t *testing.T, tags ...string) string {
+	t.Helper()
+	return initRepo(t, t.TempDir(), tags...)
+}
+
+// initRepo is newRepo in an existing directory
+func initRepo(t *testing.T, dir string, tags ...string) string {
+	t.Helper()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	runGit(t, dir, "config", "tag.gpgsign", "false")
+	for _, tag := range tags {
+		commit(t, dir, "release "+tag)
+		runGit(t, dir, "tag", tag)
+	}
+	return dir
+}
+
+// commit adds an empty commit with the given message
+func commit(t *testing.T, dir, message string) {
+	t.Helper()
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", message)
+}
+
+// writeFile creates a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	return writeFileAt(t, filepath.Join(t.TempDir(), name), content)
+}
+
+// writeFileAt writes content to path and returns it
+func writeFileAt(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// captureLog redirects slog to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	return &buf
+}
+
+func TestValidateOnly(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"valid", []string{"--major", "1", "--minor", "2"}, false},
+		{"skipped minor", []string{"--major", "1", "--minor", "5"}, true},
+		{"downgrade", []string{"--major", "0", "--minor", "1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--validate-only"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != "" {
+				t.Errorf("printed %q, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestAssertNext(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		assert  string
+		wantErr bool
+	}{
+		{"v1.2.4", false},
+		{"v1.3.0", true},
+		{"1.2.4", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.assert, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--assert-next", tt.assert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "- "+tt.assert+" (asserted) + v1.2.4 (computed)") {
+				t.Errorf("error %q lacks the diff", err)
+			}
+			if err == nil && got != "v1.2.4" {
+				t.Errorf("got %q, want v1.2.4", got)
+			}
+		})
+	}
+}
+
+func TestGlobPath(t *testing.T) {
+	parent := t.TempDir()
+	for name, tag := range map[string]string{"api": "v1.2.3", "web": "v1.2.9"} {
+		if err := os.Mkdir(filepath.Join(parent, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		initRepo(t, filepath.Join(parent, name), tag)
+	}
+	writeFileAt(t, filepath.Join(parent, "notes.txt"), "not a repository")
+
+	tests := []struct {
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{"*", "PARENT/api v1.2.4\nPARENT/web v1.2.10\n", false},
+		{"w*", "PARENT/web v1.2.10\n", false},
+		{"nothing-*", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := runArgs(t, "--path", filepath.Join(parent, tt.pattern), "--major", "1", "--minor", "2")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := strings.ReplaceAll(tt.want, "PARENT", parent); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFlagDefaults(t *testing.T) {
+	opts := parseArgs(t)
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"major", opts.Major, -1},
+		{"minor", opts.Minor, -1},
+		{"components", opts.Components, 3},
+		{"strategy", opts.Strategy, "strict"},
+		{"format", opts.Format, "plain"},
+		{"main branch", opts.MainBranch, "main"},
+		{"backend", opts.Backend, "git"},
+		{"max allowed major", opts.MaxAllowedMajor, 10000},
+		{"log format", opts.LogFormat, "text"},
+		{"log level", opts.LogLevel, "info"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFlagsFillOptions(t *testing.T) {
+	opts := parseArgs(t, "--path", "repo", "--major", "2", "--minor", "1", "--tagger", "a", "--tagger", "b", "--watch-interval", "5s")
+	if opts.Path != "repo" || opts.Major != 2 || opts.Minor != 1 {
+		t.Errorf("got path=%q major=%d minor=%d", opts.Path, opts.Major, opts.Minor)
+	}
+	if strings.Join(opts.Taggers, ",") != "a,b" {
+		t.Errorf("repeatable --tagger collected %v", opts.Taggers)
+	}
+	if opts.WatchInterval.String() != "5s" {
+		t.Errorf("watch interval = %s", opts.WatchInterval)
+	}
+	if opts = parseArgs(t, "--log-format", "json", "--log-level", "debug"); opts.LogFormat != "json" || opts.LogLevel != "debug" {
+		t.Errorf("got log format %q and level %q", opts.LogFormat, opts.LogLevel)
+	}
+}
+
+func TestMaxPatch(t *testing.T) {
+	tests := []struct {
+		latest  string
+		want    string
+		wantErr string
+	}{
+		{latest: "v1.2.4", want: "v1.2.5"},
+		{latest: "v1.2.5", wantErr: "bump the minor version instead (--minor 3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latest, func(t *testing.T) {
+			dir := newRepo(t, tt.latest)
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--max-patch", "5")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromNth(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.0\nv1.2.1\nv1.3.0\nv1.4.2\n")
+	tests := []struct {
+		nth     string
+		minor   string
+		want    string
+		wantErr string
+	}{
+		{nth: "0", minor: "4", want: "v1.4.3"},
+		{nth: "1", minor: "3", want: "v1.3.1"},
+		{nth: "3", minor: "2", wantErr: "already exists as tag v1.2.1"},
+		{nth: "4", minor: "2", wantErr: "out of range"},
+		{nth: "-1", minor: "2", wantErr: "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nth, func(t *testing.T) {
+			got, err := runArgs(t, "--tags-file", tags, "--from-nth", tt.nth, "--major", "1", "--minor", tt.minor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"feature/login": "feature-login",
+		"fix_bug#12":    "fix-bug-12",
+		"007":           "7",
+		"000":           "0",
+		"007-agent":     "007-agent",
+	}
+	for in, want := range tests {
+		if got := sanitizeIdentifier(in); got != want {
+			t.Errorf("sanitizeIdentifier(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBranchAware(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		branch  string
+		commits int
+		want    string
+	}{
+		{"main", 0, "v1.2.4"},
+		{"feature/login", 2, "v1.2.4-feature-login.2"},
+		{"007", 1, "v1.2.4-7.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			runGit(t, dir, "checkout", "-q", "-B", tt.branch, "v1.2.3")
+			for i := 0; i < tt.commits; i++ {
+				commit(t, dir, "work")
+			}
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--branch-aware")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictlyIncreasing(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.0\nv1.3.0\n")
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"--major", "1", "--minor", "3"}, false},
+		{[]string{"--major", "1", "--minor", "2", "--from-nth", "1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, err := runArgs(t, append([]string{"--tags-file", tags, "--strictly-increasing"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.0\nv1.2.1\nv1.2.7\nv1.3.0\n")
+	line := writeFile(t, "line.txt", "v1.2.0\nv1.2.1\nv1.2.7\n")
+	gap := writeFile(t, "gap.txt", "v1.2.0\nv1.2.7\n")
+	tests := []struct {
+		selection string
+		tags      string
+		minor     string
+		want      string
+		wantErr   string
+	}{
+		{"latest", tags, "3", "v1.3.1", ""},
+		{"earliest", tags, "3", "v1.3.1", ""},
+		{"earliest", tags, "4", "v1.4.0", ""},
+		{"latest", line, "2", "v1.2.8", ""},
+		{"earliest", line, "2", "", "computed version v1.2.1 from --select earliest already exists as tag v1.2.1"},
+		{"earliest", gap, "2", "v1.2.1", ""},
+		{"newest", tags, "3", "", `invalid --select "newest": must be latest or earliest`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selection+"/"+filepath.Base(tt.tags)+"/"+tt.minor, func(t *testing.T) {
+			got, err := runArgs(t, "--tags-file", tt.tags, "--select", tt.selection, "--major", "1", "--minor", tt.minor)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZeroVer(t *testing.T) {
+	tests := []struct {
+		latest  string
+		bump    string
+		zeroVer bool
+		want    string
+	}{
+		{"v0.3.2", "major", true, "v0.4.0"},
+		{"v0.3.2", "minor", true, "v0.4.0"},
+		{"v0.3.2", "patch", true, "v0.3.3"},
+		{"v0.3.2", "major", false, "v1.0.0"},
+		{"v1.3.2", "major", true, "v2.0.0"},
+		{"v1.3.2", "minor", true, "v1.4.0"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s/%t", tt.latest, tt.bump, tt.zeroVer), func(t *testing.T) {
+			got, err := runArgs(t, "--path", newRepo(t, tt.latest), "--bump", tt.bump, fmt.Sprintf("--zerover=%t", tt.zeroVer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for wantErr, args := range map[string][]string{
+		"--zerover requires --bump or --bump-trailer":              {"--path", ".", "--major", "1", "--minor", "0", "--zerover"},
+		`invalid --bump "breaking": must be patch, minor or major`: {"--path", ".", "--bump", "breaking"},
+		"--major/--minor cannot be combined with --bump":           {"--path", ".", "--bump", "minor", "--major", "1"},
+	} {
+		if err := validateOptions(parseArgs(t, args...)); err == nil || err.Error() != wantErr {
+			t.Errorf("error = %v, want %q", err, wantErr)
+		}
+	}
+}
+
+func TestLatestPerMinor(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{"no tags", nil, ""},
+		{"one line", []string{"v1.2.0", "v1.2.1"}, "v1.2.1\n"},
+		{"several lines", []string{"v1.0.0", "v1.1.0", "v1.1.3", "v2.0.0", "v1.0.4"}, "v2.0.0\nv1.1.3\nv1.0.4\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			if len(tt.tags) == 0 {
+				commit(t, dir, "initial")
+			}
+			got, err := runArgs(t, "--path", dir, "--latest-per-minor")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target    string
+		wantMajor int
+		wantMinor int
+		wantErr   bool
+	}{
+		{"1.2", 1, 2, false},
+		{"v1.2", 1, 2, false},
+		{"1.2.7", 1, 2, false},
+		{"10.20", 10, 20, false},
+		{"1", 0, 0, true},
+		{"1.x", 0, 0, true},
+		{"1.2.3.4", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			major, minor, err := parseTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if major != tt.wantMajor || minor != tt.wantMinor {
+				t.Errorf("got %d.%d, want %d.%d", major, minor, tt.wantMajor, tt.wantMinor)
+			}
+		})
+	}
+}
+
+func TestTarget(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{[]string{"--target", "1.2"}, "v1.2.4", ""},
+		{[]string{"--target", "v1.3"}, "v1.3.0", ""},
+		{[]string{"--target", "1.2", "--major", "1"}, "", "--major/--minor cannot be combined with --target"},
+		{[]string{"--target", "one.two"}, "", "invalid --target"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir}, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.TrimSpace(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarnDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		warn     bool
+		wantWarn bool
+	}{
+		{"no tags", nil, true, true},
+		{"no tags without flag", nil, false, false},
+		{"tagged", []string{"v1.0.0"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			commit(t, dir, "change")
+			logs := captureLog(t)
+			args := []string{"--path", dir, "--major", "1", "--minor", "0"}
+			if tt.warn {
+				args = append(args, "--warn-default")
+			}
+			if _, err := runArgs(t, args...); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(logs.String(), "no matching semver tags found"); got != tt.wantWarn {
+				t.Errorf("warned = %v, want %v: %s", got, tt.wantWarn, logs)
+			}
+		})
+	}
+}
+
+func TestKeepLatestMinor(t *testing.T) {
+	dir := newRepo(t, "v1.2.3", "v2.0.1")
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{[]string{"--major", "2"}, "v2.0.2", false},
+		{[]string{"--major", "3"}, "", true},
+		{[]string{"--major", "1"}, "", true},
+		{[]string{"--major", "2", "--minor", "1"}, "v2.1.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrefixMap(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"api=api-v,web=web-", "api:api-v web:web-", false},
+		{" api=api-v , web=web- ", "api:api-v web:web-", false},
+		{"api", "", true},
+		{"api=", "", true},
+		{"=api-v", "", true},
+		{"api=api-v,", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			services, err := parsePrefixMap(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, service := range services {
+				got = append(got, service[0]+":"+service[1])
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixMap(t *testing.T) {
+	dir := newRepo(t, "api-v1.2.3", "web-1.2.0", "v9.0.0")
+	got, err := runArgs(t, "--path", dir, "--prefix-map", "api=api-v,web=web-,new=new-v", "--major", "1", "--minor", "2")
+	if err == nil || !strings.Contains(err.Error(), "new: ") {
+		t.Errorf("error = %v, want the new service to fail to skip to 1.2", err)
+	}
+	if want := "api api-v1.2.4\nweb web-1.2.1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPatchFromCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		commits int
+		minor   string
+		want    string
+		wantErr bool
+	}{
+		{"commits since the first tag", []string{"v1.2.0", "v1.2.1"}, 3, "2", "v1.2.4", false},
+		{"minor bump unchanged", []string{"v1.2.0"}, 3, "3", "v1.3.0", false},
+		{"behind the latest patch", []string{"v1.2.0", "v1.2.7"}, 1, "2", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			for range tt.commits {
+				commit(t, dir, "change")
+			}
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", tt.minor, "--patch-from-commits")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarliestInLine(t *testing.T) {
+	tags, _ := parseSemverTags([]string{"v1.2.0", "v1.2.1", "v1.2.7", "v1.3.0"}, Options{})
+	tests := []struct {
+		from SemVer
+		want string
+	}{
+		{tags[1], "v1.2.0"},
+		{tags[0], "v1.3.0"},
+	}
+	for _, tt := range tests {
+		if got := earliestInLine(tags, tt.from); got.Raw != tt.want {
+			t.Errorf("earliestInLine(%s) = %s, want %s", tt.from.Raw, got.Raw, tt.want)
+		}
+	}
+}
+
+func TestInferMajor(t *testing.T) {
+	dir := newRepo(t, "v1.2.3", "v2.4.1")
+	tests := []struct {
+		minor   string
+		want    string
+		wantErr bool
+	}{
+		{"4", "v2.4.2", false},
+		{"5", "v2.5.0", false},
+		{"3", "", true},
+		{"6", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.minor, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--minor", tt.minor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailOnDowngradeAttempt(t *testing.T) {
+	runMainIfRequested()
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"downgrade", []string{"--major", "1", "--minor", "1", "--fail-on-downgrade-attempt"}, exitDowngrade},
+		{"skip", []string{"--major", "1", "--minor", "5", "--fail-on-downgrade-attempt"}, 1},
+		{"downgrade without flag", []string{"--major", "1", "--minor", "1"}, 1},
+		{"valid", []string{"--major", "1", "--minor", "2", "--fail-on-downgrade-attempt"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(t, append([]string{"--path", dir, "--log-level", "error"}, tt.args...)...); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckIfPathExists(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFileAt(t, filepath.Join(dir, "file.txt"), "")
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{dir, ""},
+		{file, "is not a directory"},
+		{filepath.Join(dir, "missing"), "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			err := checkIfPathExists(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNoDefaultStart(t *testing.T) {
+	runMainIfRequested()
+	dir := newRepo(t)
+	commit(t, dir, "initial")
+
+	got, err := runArgs(t, "--path", dir, "--major", "0", "--minor", "1", "--no-default-start")
+	if !errors.Is(err, errNoTags) || got != "" {
+		t.Errorf("got %q, %v; want no output and errNoTags", got, err)
+	}
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"--no-default-start"}, 0},
+		{[]string{"--no-default-start", "--no-default-start-code", "4"}, 4},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := exitCode(t, append([]string{"--path", dir, "--major", "0", "--minor", "1"}, tt.args...)...); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendValidation(t *testing.T) {
+	err := validateOptions(parseArgs(t, "--path", ".", "--major", "1", "--minor", "0", "--backend", "libgit2"))
+	if err == nil || !strings.Contains(err.Error(), "invalid --backend") {
+		t.Errorf("error = %v, want an invalid backend", err)
+	}
+}
+
+func TestWatchRequiresLocalRepository(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.3\n")
+	for _, source := range [][]string{{"--tags-file", tags}, {"--github-repo", "owner/name"}} {
+		args := append(source, "--watch", "--major", "1", "--minor", "2")
+		if err := validateOptions(parseArgs(t, args...)); err == nil {
+			t.Errorf("%v: expected --watch to be rejected", source)
+		}
+	}
+}
+
+func TestExternalTagsRejectGitFeatures(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.3\n")
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--require-clean"}, "--require-clean requires a local --path"},
+		{[]string{"--from-branch"}, "--from-branch requires a local --path"},
+		{[]string{"--verify-signature"}, "--verify-signature requires a local --path"},
+		{[]string{"--branch-aware"}, "--branch-aware requires a local --path"},
+		{[]string{"--patch-from-commits"}, "--patch-from-commits requires a local --path"},
+		{[]string{"--write-notes"}, "--write-notes requires a local --path"},
+		{[]string{"--min-interval", "1h"}, "--min-interval requires a local --path"},
+		{[]string{"--bump-trailer", "Version-Bump"}, "--bump-trailer requires a local --path"},
+		{[]string{"--tagger", "me"}, "--tags-file cannot be combined with"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			args := append([]string{"--tags-file", tags, "--major", "1", "--minor", "2"}, tt.args...)
+			err := validateOptions(parseArgs(t, args...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNoGit(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.3\n")
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--tags-file", tags}, ""},
+		{[]string{"--github-repo", "owner/name"}, ""},
+		{nil, "--no-git requires --tags-file or --github-repo"},
+		{[]string{"--tags-file", tags, "--path", "."}, "--no-git cannot be combined with --path"},
+		{[]string{"--tags-file", tags, "--branch-aware"}, "--branch-aware needs git and cannot be combined with --no-git"},
+		{[]string{"--tags-file", tags, "--verify-signature"}, "--verify-signature needs git and cannot be combined with --no-git"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			args := append([]string{"--no-git", "--major", "1", "--minor", "2"}, tt.args...)
+			err := validateOptions(parseArgs(t, args...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := runArgs(t, "--no-git", "--tags-file", tags, "--major", "1", "--minor", "2")
+	if err != nil || got != "v1.2.4" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+func TestRunWritesToOut(t *testing.T) {
+	dir := newRepo(t, "v1.2.3", "v1.3.0")
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	defaultStdout := os.Stdout
+	t.Cleanup(func() { os.Stdout = defaultStdout })
+	os.Stdout = stdout
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--major", "1", "--minor", "3"}, "v1.3.1"},
+		{[]string{"--latest-per-minor"}, "v1.3.0\nv1.2.3\n"},
+		{[]string{"--major", "1", "--minor", "3", "--format", "json"}, `{"version":"v1.3.1","major":1,"minor":3,"patch":1,"latest":"v1.3.0"}`},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(parseArgs(t, append([]string{"--path", dir}, tt.args...)...), &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+	if info, _ := stdout.Stat(); info.Size() != 0 {
+		t.Errorf("wrote %d bytes to stdout", info.Size())
+	}
+}
+
+func TestMaxAllowed(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--major", "5", "--minor", "9", "--max-allowed-major", "5", "--max-allowed-minor", "9"}, ""},
+		{[]string{"--major", "999999999", "--minor", "0", "--max-allowed-major", "100"}, "major 999999999 is above --max-allowed-major 100"},
+		{[]string{"--major", "1", "--minor", "1000", "--max-allowed-minor", "100"}, "minor 1000 is above --max-allowed-minor 100"},
+		{[]string{"--target", "101.0", "--max-allowed-major", "100"}, "major 101 is above --max-allowed-major 100"},
+		{[]string{"--major", "999999999", "--minor", "0"}, "major 999999999 is above --max-allowed-major 10000"},
+		{[]string{"--major", "999999999", "--minor", "0", "--max-allowed-major", "0"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			err := validateOptions(parseArgs(t, append([]string{"--path", "."}, tt.args...)...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
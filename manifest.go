@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// manifestEntry is one repository of a --manifest batch
+type manifestEntry struct {
+	Path  string `json:"path"`
+	Major int    `json:"major"`
+	Minor int    `json:"minor"`
+	// Expected is the version --diff compares the computed one against
+	Expected string `json:"expected,omitempty"`
+}
+
+// parseManifest reads "<path> <major>.<minor> [<expected>]" lines, skipping blanks and
+// # comments, or a JSON array of entries. Relative paths are resolved
+// against the manifest's directory.
+func parseManifest(file string) ([]manifestEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var entries []manifestEntry
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", file, err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 || len(fields) > 3 {
+				return nil, fmt.Errorf("invalid manifest %s line %d: expected <path> <major>.<minor> [<expected>]", file, n)
+			}
+			major, minor, err := parseTarget(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid manifest %s line %d: %w", file, n, err)
+			}
+			entry := manifestEntry{Path: fields[0], Major: major, Minor: minor}
+			if len(fields) == 3 {
+				entry.Expected = fields[2]
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	for i, entry := range entries {
+		if entry.Path == "" {
+			return nil, fmt.Errorf("invalid manifest %s: entry %d has no path", file, i+1)
+		}
+		if !filepath.IsAbs(entry.Path) {
+			entries[i].Path = filepath.Join(filepath.Dir(file), entry.Path)
+		}
+	}
+	return entries, nil
+}
+
+// runManifest computes every entry of the manifest and prints a table of
+// the results, collecting errors per entry
+func runManifest(opts Options, out io.Writer) error {
+	entries, err := parseManifest(opts.Manifest)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tLATEST\tNEXT")
+	var errs []error
+	for _, entry := range entries {
+		entryOpts := opts
+		entryOpts.Path, entryOpts.Major, entryOpts.Minor = entry.Path, entry.Major, entry.Minor
+		latestTag, nextVersion, err := computeNextVersion(entry.Path, entryOpts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+			fmt.Fprintf(w, "%s\t-\terror\n", entry.Path)
+			continue
+		}
+		if opts.BumpLog != "" {
+			appendBumpLog(entryOpts, entry.Path, latestTag, nextVersion)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Path, FormatTag(latestTag, opts), formatVersion(latestTag, nextVersion, entryOpts))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// runDiff computes every entry of the manifest and reports those whose next
+// version differs from the expected one
+func runDiff(opts Options, out io.Writer) error {
+	entries, err := parseManifest(opts.Diff)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	mismatches := 0
+	for _, entry := range entries {
+		if entry.Expected == "" {
+			errs = append(errs, fmt.Errorf("%s: no expected version in the manifest", entry.Path))
+			continue
+		}
+		entryOpts := opts
+		entryOpts.Path, entryOpts.Major, entryOpts.Minor = entry.Path, entry.Major, entry.Minor
+		_, nextVersion, err := computeNextVersion(entry.Path, entryOpts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+			continue
+		}
+		if computed := FormatTag(nextVersion, entryOpts); computed != entry.Expected {
+			fmt.Fprintf(out, "%s: expected %s, computed %s\n", entry.Path, entry.Expected, computed)
+			mismatches++
+		}
+	}
+	if mismatches > 0 {
+		errs = append(errs, fmt.Errorf("%d of %d repositories differ from the manifest", mismatches, len(entries)))
+	}
+	return errors.Join(errs...)
+}
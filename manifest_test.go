@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"lines", "# services\napi 1.2\n\n/srv/web v2.0 v2.0.1\n", "DIR/api 1.2 ,/srv/web 2.0 v2.0.1", false},
+		{"json", `[{"path": "api", "major": 1, "minor": 2}, {"path": "/srv/web", "major": 2, "minor": 0, "expected": "v2.0.1"}]`, "DIR/api 1.2 ,/srv/web 2.0 v2.0.1", false},
+		{"missing version", "api\n", "", true},
+		{"bad version", "api one.two\n", "", true},
+		{"too many fields", "api 1.2 v1.2.4 extra\n", "", true},
+		{"json without path", `[{"major": 1, "minor": 2}]`, "", true},
+		{"bad json", `[{"path": 1}]`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeFile(t, "manifest", tt.content)
+			entries, err := parseManifest(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, fmt.Sprintf("%s %d.%d %s", entry.Path, entry.Major, entry.Minor, entry.Expected))
+			}
+			if want := strings.ReplaceAll(tt.want, "DIR", filepath.Dir(file)); strings.Join(got, ",") != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	root := t.TempDir()
+	for name, tag := range map[string]string{"api": "v1.2.3", "web": "v2.0.0"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		initRepo(t, dir, tag)
+	}
+	manifest := writeFileAt(t, filepath.Join(root, "manifest"), "api 1.3\nweb 2.2\n")
+
+	got, err := runArgs(t, "--manifest", manifest)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(root, "web")+": ") {
+		t.Errorf("error = %v, want the web skip to fail", err)
+	}
+	want := [][]string{
+		{"PATH", "LATEST", "NEXT"},
+		{filepath.Join(root, "api"), "v1.2.3", "v1.3.0"},
+		{filepath.Join(root, "web"), "-", "error"},
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %q", got)
+	}
+	for i, line := range lines {
+		if fields := strings.Fields(line); !slices.Equal(fields, want[i]) {
+			t.Errorf("line %d = %q, want %q", i, fields, want[i])
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	root := t.TempDir()
+	for name, tag := range map[string]string{"api": "v1.2.3", "web": "v2.0.0", "db": "v0.1.0"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		initRepo(t, dir, tag)
+	}
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+		wantErr  string
+	}{
+		{"all match", "api 1.3 v1.3.0\nweb 2.0 v2.0.1\n", "", ""},
+		{"mismatch", "api 1.3 v1.3.0\nweb 2.0 v2.1.0\n", "ROOT/web: expected v2.1.0, computed v2.0.1\n", "1 of 2 repositories differ from the manifest"},
+		{"no expected version", "db 0.1\n", "", "ROOT/db: no expected version in the manifest"},
+		{"compute error", "db 0.5 v0.5.0\n", "", "ROOT/db: invalid minor version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := writeFileAt(t, filepath.Join(root, "manifest"), tt.manifest)
+			got, err := runArgs(t, "--diff", manifest)
+			wantErr := strings.ReplaceAll(tt.wantErr, "ROOT", root)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+				t.Fatalf("error = %v, want %q", err, wantErr)
+			}
+			if want := strings.ReplaceAll(tt.want, "ROOT", root); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
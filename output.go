@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9._+:@/-]+$`)
+
+// FormatTag renders the tag name of a version. Every place that prints or
+// writes a tag name goes through it so they never disagree.
+func FormatTag(v SemVer, opts Options) string {
+	if opts.PrefixCase == "upper" || opts.PrefixCase == "lower" {
+		prefix := v.Prefix
+		if prefix == "" {
+			prefix = "v"
+		}
+		if opts.PrefixCase == "upper" {
+			v.Prefix = strings.ToUpper(prefix)
+		} else {
+			v.Prefix = strings.ToLower(prefix)
+		}
+	}
+	return v.String()
+}
+
+// printResult prints the single result of a run, without a trailing newline
+// unless --output-newline is set
+func printResult(out io.Writer, s string, opts Options) {
+	if opts.OutputNewline {
+		s += "\n"
+	}
+	fmt.Fprint(out, s)
+}
+
+func formatVersion(latestTag, v SemVer, opts Options) string {
+	s := renderVersion(latestTag, v, opts)
+	if opts.StripV {
+		// Only the final string changes, not which tags are read
+		s = strings.TrimPrefix(s, "v")
+	}
+	return s
+}
+
+// renderVersion is formatVersion before --strip-v
+func renderVersion(latestTag, v SemVer, opts Options) string {
+	if opts.CompareURLBase != "" {
+		return compareURL(opts.CompareURLBase, latestTag)
+	}
+	if opts.TransitionLabel {
+		return transitionLabel(BumpKind(latestTag, v))
+	}
+	if opts.PrintLatestRaw {
+		// Empty when starting from Zero, as there is no tag
+		return latestTag.Raw
+	}
+	if opts.MinorOnly {
+		return FormatTag(SemVer{Epoch: v.Epoch, Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Components: 2}, opts)
+	}
+	switch opts.stdoutFormat() {
+	case "":
+		return ""
+	case "json":
+		return string(versionJSON(latestTag, v, opts))
+	case "shell":
+		var exports []string
+		for _, kv := range versionVars(v, opts) {
+			exports = append(exports, fmt.Sprintf("export %s=%s", kv[0], shellQuote(kv[1])))
+		}
+		return strings.Join(exports, "; ")
+	case "int":
+		n, _ := encodeVersionInt(v, opts.IntWidth)
+		return strconv.FormatInt(n, 10)
+	}
+	return FormatTag(v, opts)
+}
+
+// versionJSON renders the json output format
+func versionJSON(latestTag, v SemVer, opts Options) []byte {
+	data, _ := json.Marshal(struct {
+		Version    string `json:"version"`
+		Major      int    `json:"major"`
+		Minor      int    `json:"minor"`
+		Patch      int    `json:"patch"`
+		Prerelease string `json:"prerelease,omitempty"`
+		Latest     string `json:"latest"`
+	}{FormatTag(v, opts), v.Major, v.Minor, v.Patch, v.Prerelease, FormatTag(latestTag, opts)})
+	return data
+}
+
+// encodeVersionInt packs major, minor and patch into one sortable integer,
+// giving minor and patch width decimal digits each
+func encodeVersionInt(v SemVer, width int) (int64, error) {
+	base := int64(math.Pow10(width))
+	if int64(v.Minor) >= base || int64(v.Patch) >= base {
+		return 0, fmt.Errorf("version %s does not fit in %d digits per component", v, width)
+	}
+	if int64(v.Major) > (math.MaxInt64-base*base)/(base*base) {
+		return 0, fmt.Errorf("major version %d overflows the integer encoding", v.Major)
+	}
+	return int64(v.Major)*base*base + int64(v.Minor)*base + int64(v.Patch), nil
+}
+
+// transitionLabel turns a bump kind into a human release title
+func transitionLabel(kind string) string {
+	switch kind {
+	case "major":
+		return "Major breaking release"
+	case "minor":
+		return "Minor feature release"
+	case "patch":
+		return "Patch release"
+	}
+	return "No version change"
+}
+
+// versionVars returns the SEMVER_* variable names and values describing v
+func versionVars(v SemVer, opts Options) [][2]string {
+	vars := [][2]string{
+		{"SEMVER_VERSION", FormatTag(v, opts)},
+		{"SEMVER_MAJOR", strconv.Itoa(v.Major)},
+		{"SEMVER_MINOR", strconv.Itoa(v.Minor)},
+		{"SEMVER_PATCH", strconv.Itoa(v.Patch)},
+	}
+	if v.Prerelease != "" {
+		vars = append(vars, [2]string{"SEMVER_PRERELEASE", v.Prerelease})
+	}
+	return vars
+}
+
+// writeEnvFile writes the SEMVER_* variables of v as a dotenv file
+func writeEnvFile(path string, v SemVer, opts Options) error {
+	var b strings.Builder
+	for _, kv := range versionVars(v, opts) {
+		fmt.Fprintf(&b, "%s=%s\n", kv[0], dotenvQuote(kv[1]))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// dotenvQuote double-quotes s, escaping backslashes, quotes and dollars,
+// unless it is safe unquoted
+func dotenvQuote(s string) string {
+	if shellSafeRegex.MatchString(s) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`).Replace(s) + `"`
+}
+
+// shellQuote single-quotes s unless it only contains characters that are safe
+// unquoted in POSIX shells
+func shellQuote(s string) string {
+	if shellSafeRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// compareURL links the changes since the latest tag, or all commits when
+// there is no tag yet
+func compareURL(base string, latestTag SemVer) string {
+	base = strings.TrimSuffix(base, "/")
+	if latestTag.Raw == "" {
+		return base + "/commits/HEAD"
+	}
+	return fmt.Sprintf("%s/compare/%s...HEAD", base, latestTag.Raw)
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMinorOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		v    SemVer
+		opts Options
+		want string
+	}{
+		{"plain", SemVer{Major: 1, Minor: 2, Patch: 7}, Options{}, "v1.2"},
+		{"prefix case", SemVer{Major: 1, Minor: 2, Patch: 7}, Options{PrefixCase: "upper"}, "V1.2"},
+		{"custom prefix", SemVer{Prefix: "api-v", Major: 1, Minor: 2, Patch: 7}, Options{}, "api-v1.2"},
+		{"epoch", SemVer{Epoch: 1, Major: 2, Minor: 0, Patch: 1}, Options{}, "1!v2.0"},
+		{"four components", SemVer{Major: 1, Minor: 2, Patch: 3, Revision: 4, Components: 4}, Options{}, "v1.2"},
+		{"prerelease dropped", SemVer{Major: 1, Minor: 3, Prerelease: "rc.1"}, Options{}, "v1.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.MinorOnly = true
+			if got := formatVersion(SemVer{}, tt.v, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	tests := []struct {
+		name, base string
+		latest     SemVer
+		want       string
+	}{
+		{"tagged", "https://github.com/o/r", SemVer{Major: 1, Minor: 2, Patch: 3, Raw: "v1.2.3"}, "https://github.com/o/r/compare/v1.2.3...HEAD"},
+		{"trailing slash", "https://github.com/o/r/", SemVer{Major: 1, Raw: "api-v1.0.0"}, "https://github.com/o/r/compare/api-v1.0.0...HEAD"},
+		{"no tags", "https://github.com/o/r", Zero(), "https://github.com/o/r/commits/HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareURL(tt.base, tt.latest); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		v    SemVer
+		opts Options
+		want string
+	}{
+		{"release", SemVer{Major: 1, Minor: 2, Patch: 4}, Options{},
+			"export SEMVER_VERSION=v1.2.4; export SEMVER_MAJOR=1; export SEMVER_MINOR=2; export SEMVER_PATCH=4"},
+		{"prerelease", SemVer{Major: 2, Prerelease: "rc.1"}, Options{},
+			"export SEMVER_VERSION=v2.0.0-rc.1; export SEMVER_MAJOR=2; export SEMVER_MINOR=0; export SEMVER_PATCH=0; export SEMVER_PRERELEASE=rc.1"},
+		{"quoted", SemVer{Prefix: "it's v", Major: 1}, Options{},
+			`export SEMVER_VERSION='it'\''s v1.0.0'; export SEMVER_MAJOR=1; export SEMVER_MINOR=0; export SEMVER_PATCH=0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Format = "shell"
+			if got := formatVersion(Zero(), tt.v, tt.opts); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"v1.2.3", "v1.2.3"},
+		{"", "''"},
+		{"a b", "'a b'"},
+		{"$(rm -rf /)", "'$(rm -rf /)'"},
+		{"it's", `'it'\''s'`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionLabel(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		major, minor string
+		want         string
+	}{
+		{"1", "2", "Patch release"},
+		{"1", "3", "Minor feature release"},
+		{"2", "0", "Major breaking release"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--major", tt.major, "--minor", tt.minor, "--transition-label")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := transitionLabel(BumpKind(Zero(), Zero())); got != "No version change" {
+		t.Errorf("unchanged version: got %q", got)
+	}
+}
+
+func TestEncodeVersionInt(t *testing.T) {
+	tests := []struct {
+		v       SemVer
+		width   int
+		want    int64
+		wantErr bool
+	}{
+		{SemVer{Major: 1, Minor: 2, Patch: 3}, 3, 1_002_003, false},
+		{SemVer{Major: 12, Minor: 345, Patch: 6}, 3, 12_345_006, false},
+		{SemVer{Major: 1, Minor: 2, Patch: 3}, 2, 10_203, false},
+		{SemVer{Major: 1, Minor: 1000}, 3, 0, true},
+		{SemVer{Major: 1, Patch: 100}, 2, 0, true},
+		{SemVer{Major: 9_223_372}, 6, 0, true},
+		{SemVer{Major: 9_223_371, Minor: 999_999, Patch: 999_999}, 6, 9_223_371_999_999_999_999, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v.String(), func(t *testing.T) {
+			got, err := encodeVersionInt(tt.v, tt.width)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatInt(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{[]string{"--minor", "2"}, "1002004", false},
+		{[]string{"--minor", "3", "--int-width", "2"}, "10300", false},
+		{[]string{"--minor", "2", "--int-width", "7"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--format", "int", "--major", "1"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultipleFormats(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		name     string
+		format   string
+		jsonFile bool
+		want     string
+		wantJSON string
+		wantErr  bool
+	}{
+		{"plain and json file", "plain,json", true, "v1.2.4", `{"version":"v1.2.4","major":1,"minor":2,"patch":4,"latest":"v1.2.3"}` + "\n", false},
+		{"json file only", "json", true, "", `{"version":"v1.2.4","major":1,"minor":2,"patch":4,"latest":"v1.2.3"}` + "\n", false},
+		{"two stdout formats", "plain,int", false, "", "", true},
+		{"json file without json", "plain", true, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"--path", dir, "--major", "1", "--minor", "2", "--format", tt.format}
+			jsonFile := filepath.Join(t.TempDir(), "version.json")
+			if tt.jsonFile {
+				args = append(args, "--format-json-file", jsonFile)
+			}
+			got, err := runArgs(t, args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.wantJSON != "" {
+				data, err := os.ReadFile(jsonFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != tt.wantJSON {
+					t.Errorf("json file = %q, want %q", data, tt.wantJSON)
+				}
+			}
+		})
+	}
+}
+
+func TestDotenvQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"v1.2.3", "v1.2.3"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`$HOME\x`, `"\$HOME\\x"`},
+		{"two\nlines", `"two\nlines"`},
+	}
+	for _, tt := range tests {
+		if got := dotenvQuote(tt.in); got != tt.want {
+			t.Errorf("dotenvQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnvFile(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	envFile := filepath.Join(t.TempDir(), ".env")
+	got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--env-file", envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4" {
+		t.Errorf("stdout = %q, want v1.2.4", got)
+	}
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SEMVER_VERSION=v1.2.4\nSEMVER_MAJOR=1\nSEMVER_MINOR=2\nSEMVER_PATCH=4\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestOutputNewline(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "v1.2.4"},
+		{[]string{"--output-newline"}, "v1.2.4\n"},
+		{[]string{"--output-newline", "--format", "int"}, "1002004\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--major", "1", "--minor", "2"}, tt.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTagPrefixCase(t *testing.T) {
+	tests := []struct {
+		v      SemVer
+		prefix string
+		want   string
+	}{
+		{SemVer{Major: 1, Minor: 2, Patch: 3}, "upper", "V1.2.3"},
+		{SemVer{Major: 1, Minor: 2, Patch: 3}, "lower", "v1.2.3"},
+		{SemVer{Major: 1, Minor: 2, Patch: 3}, "preserve", "v1.2.3"},
+		{SemVer{Prefix: "Api-V", Major: 1}, "lower", "api-v1.0.0"},
+		{SemVer{Prefix: "Api-V", Major: 1}, "preserve", "Api-V1.0.0"},
+		{SemVer{Major: 1, Prerelease: "rc.1", Edition: "ce"}, "upper", "V1.0.0-ce-rc.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix+"/"+tt.want, func(t *testing.T) {
+			if got := FormatTag(tt.v, Options{PrefixCase: tt.prefix}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixCase(t *testing.T) {
+	// Tags created from uppercase output are read back
+	dir := newRepo(t, "v1.2.3", "V1.2.4")
+	got, err := runArgs(t, "--path", dir, "--prefix-case", "upper", "--major", "1", "--minor", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "V1.2.5" {
+		t.Errorf("got %q, want V1.2.5", got)
+	}
+	if err := validateOptions(parseArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--prefix-case", "title")); err == nil {
+		t.Error("expected an invalid --prefix-case to be rejected")
+	}
+}
+
+func TestPrintLatestRaw(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		args []string
+		want string
+	}{
+		{"canonical", []string{"v1.2.3"}, nil, "v1.2.3"},
+		{"stripped prefix", []string{"v1.2.3", "release-1.3.0"}, []string{"--strip-prefix", "release-"}, "release-1.3.0"},
+		{"namespace", []string{"services/api/v2.0.0"}, []string{"--tag-namespace", "services/api"}, "services/api/v2.0.0"},
+		{"no tags", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			if len(tt.tags) == 0 {
+				commit(t, dir, "initial")
+			}
+			got, err := runArgs(t, append([]string{"--path", dir, "--print-latest-raw", "--strategy", "lenient", "--major", "9", "--minor", "0"}, tt.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripV(t *testing.T) {
+	tests := []struct {
+		name string
+		v    SemVer
+		opts Options
+		want string
+	}{
+		{"plain", SemVer{Major: 1, Minor: 2, Patch: 4}, Options{}, "1.2.4"},
+		{"uppercase prefix kept", SemVer{Major: 1, Minor: 2, Patch: 4}, Options{PrefixCase: "upper"}, "V1.2.4"},
+		{"custom prefix kept", SemVer{Prefix: "api-v", Major: 1}, Options{}, "api-v1.0.0"},
+		{"minor only", SemVer{Major: 1, Minor: 2, Patch: 4}, Options{MinorOnly: true}, "1.2"},
+		{"json untouched", SemVer{Major: 1, Minor: 2, Patch: 4}, Options{Format: "json"}, `{"version":"v1.2.4","major":1,"minor":2,"patch":4,"latest":"v0.0.0"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.StripV = true
+			if got := formatVersion(Zero(), tt.v, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
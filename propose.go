@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// proposalToken stamps a proposed version with the state it was computed
+// from: the commit to tag and the latest tag. Any change to either yields a
+// different token.
+func proposalToken(path string, latestTag, nextVersion SemVer, opts Options) (string, error) {
+	head, err := resolveRev(path, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	nextTag := FormatTag(nextVersion, opts)
+	sum := sha256.Sum256([]byte(head + "\n" + latestTag.Raw + "\n" + nextTag))
+	return nextTag + "@" + hex.EncodeToString(sum[:6]), nil
+}
+
+// runPropose prints the token for the next version without tagging
+func runPropose(opts Options, out io.Writer) error {
+	latestTag, nextVersion, err := computeNextVersion(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+	token, err := proposalToken(opts.Path, latestTag, nextVersion, opts)
+	if err != nil {
+		return err
+	}
+	if opts.ValidateOnly {
+		return nil
+	}
+	printResult(out, token, opts)
+	return nil
+}
+
+// runCommit recomputes the next version and tags HEAD with it only if the
+// result still matches the --commit token from --propose, then records and
+// prints it like any other bump
+func runCommit(opts Options, out io.Writer) error {
+	latestTag, nextVersion, err := computeNextVersion(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+	token, err := proposalToken(opts.Path, latestTag, nextVersion, opts)
+	if err != nil {
+		return err
+	}
+	if token != opts.Commit {
+		proposed, _, _ := strings.Cut(opts.Commit, "@")
+		return fmt.Errorf("proposal %s is stale: HEAD or the tags changed since it was made, the next version is now %s", proposed, FormatTag(nextVersion, opts))
+	}
+	if opts.ValidateOnly {
+		return nil
+	}
+
+	if err := beforeBump(latestTag, nextVersion, opts); err != nil {
+		return err
+	}
+	nextTag := FormatTag(nextVersion, opts)
+	if err := createTag(opts.Path, nextTag); err != nil {
+		// Retrying at the next patch would tag a version that was never
+		// proposed, so a concurrent tag makes the proposal stale instead
+		if _, revErr := resolveRev(opts.Path, "refs/tags/"+nextTag); revErr == nil {
+			return fmt.Errorf("proposal %s is stale: the tag was created concurrently; run --propose again", nextTag)
+		}
+		return err
+	}
+	return afterBump(latestTag, nextVersion, opts, out)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProposeCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		between func(t *testing.T, dir string)
+		token   func(token string) string
+		want    string
+		wantErr string
+	}{
+		{name: "valid", want: "v1.2.4"},
+		{
+			name:    "new commit",
+			between: func(t *testing.T, dir string) { commit(t, dir, "another change") },
+			wantErr: "proposal v1.2.4 is stale: HEAD or the tags changed since it was made, the next version is now v1.2.4",
+		},
+		{
+			name:    "new tag",
+			between: func(t *testing.T, dir string) { runGit(t, dir, "tag", "v1.2.4") },
+			wantErr: "proposal v1.2.4 is stale: HEAD or the tags changed since it was made, the next version is now v1.2.5",
+		},
+		{
+			name:    "tampered token",
+			token:   func(token string) string { return strings.Replace(token, "v1.2.4", "v1.3.0", 1) },
+			wantErr: "proposal v1.3.0 is stale: HEAD or the tags changed since it was made, the next version is now v1.2.4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.3")
+			commit(t, dir, "change")
+			token, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(token, "v1.2.4@") {
+				t.Fatalf("token = %q, want v1.2.4@<stamp>", token)
+			}
+			if tt.between != nil {
+				tt.between(t, dir)
+			}
+			if tt.token != nil {
+				token = tt.token(token)
+			}
+			before := runGit(t, dir, "tag")
+
+			got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--commit", token)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if after := runGit(t, dir, "tag"); after != before {
+					t.Errorf("stale proposal changed the tags from %q to %q", before, after)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tags := runGit(t, dir, "tag", "--points-at", "HEAD"); tags != tt.want {
+				t.Errorf("HEAD tags = %q, want %q", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitConcurrentTag(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	commit(t, dir, "change")
+	token, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another pipeline tags the proposed version between the check and the tag
+	script := filepath.Join(t.TempDir(), "race.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ngit -C '"+dir+"' tag \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--commit", token, "--pre-bump-script", script)
+	if err == nil || !strings.Contains(err.Error(), "proposal v1.2.4 is stale: the tag was created concurrently") {
+		t.Fatalf("error = %v, want a stale proposal", err)
+	}
+}
+
+func TestCommitRecordsBump(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		args   func(files string) []string
+		file   string
+		want   string
+		stdout string
+	}{
+		{
+			name: "update file",
+			args: func(files string) []string {
+				return []string{"--update-file", filepath.Join(files, "version.go"), "--update-pattern", `Version = "([^"]+)"`}
+			},
+			file:   "version.go",
+			want:   "const Version = \"v1.2.4\"\n",
+			stdout: "v1.2.4",
+		},
+		{
+			name:   "bump log",
+			args:   func(files string) []string { return []string{"--bump-log", filepath.Join(files, "bumps.jsonl")} },
+			file:   "bumps.jsonl",
+			want:   `"latest":"v1.2.3","version":"v1.2.4","bump":"patch"}` + "\n",
+			stdout: "v1.2.4",
+		},
+		{
+			name:   "github output",
+			args:   func(files string) []string { return []string{"--github-output"} },
+			file:   "github_output",
+			want:   "version=v1.2.4\nmajor=1\nminor=2\npatch=4\n",
+			stdout: "v1.2.4",
+		},
+		{
+			name:   "env file",
+			args:   func(files string) []string { return []string{"--env-file", filepath.Join(files, ".env")} },
+			file:   ".env",
+			want:   "SEMVER_VERSION=v1.2.4\nSEMVER_MAJOR=1\nSEMVER_MINOR=2\nSEMVER_PATCH=4\n",
+			stdout: "v1.2.4",
+		},
+		{
+			name: "format json file",
+			args: func(files string) []string {
+				return []string{"--format", "json", "--format-json-file", filepath.Join(files, "version.json")}
+			},
+			file:   "version.json",
+			want:   `{"version":"v1.2.4","major":1,"minor":2,"patch":4,"latest":"v1.2.3"}` + "\n",
+			stdout: "",
+		},
+		{
+			name:   "format",
+			args:   func(files string) []string { return []string{"--format", "int"} },
+			stdout: "1002004",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, "v1.2.3")
+			commit(t, dir, "change")
+			files := t.TempDir()
+			writeFileAt(t, filepath.Join(files, "version.go"), "const Version = \"v1.2.3\"\n")
+			t.Setenv("GITHUB_OUTPUT", filepath.Join(files, "github_output"))
+			token, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := runArgs(t, append([]string{"--path", dir, "--major", "1", "--minor", "2", "--commit", token}, tt.args(files)...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.stdout {
+				t.Errorf("stdout = %q, want %q", got, tt.stdout)
+			}
+			if tags := runGit(t, dir, "tag", "--points-at", "HEAD"); tags != "v1.2.4" {
+				t.Errorf("HEAD tags = %q, want v1.2.4", tags)
+			}
+			if tt.file != "" {
+				data, err := os.ReadFile(filepath.Join(files, tt.file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasSuffix(string(data), tt.want) {
+					t.Errorf("%s = %q, want it to end with %q", tt.file, data, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitWritesNote(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	commit(t, dir, "change")
+	token, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--commit", token, "--write-notes"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := runGit(t, dir, "notes", "--ref", "semver", "show", "v1.2.4"), "v1.2.3 -> v1.2.4 (patch)\ninputs: major=1 minor=2\ncommits: 1"; got != want {
+		t.Errorf("note = %q, want %q", got, want)
+	}
+}
+
+func TestProposeCommitValidateOnly(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	commit(t, dir, "change")
+	got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose", "--validate-only")
+	if err != nil || got != "" {
+		t.Fatalf("--propose --validate-only = %q, %v; want no output", got, err)
+	}
+	token, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--propose")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--commit", token, "--validate-only")
+	if err != nil || got != "" {
+		t.Fatalf("--commit --validate-only = %q, %v; want no output", got, err)
+	}
+	if tags := runGit(t, dir, "tag"); tags != "v1.2.3" {
+		t.Errorf("--validate-only changed the tags to %q", tags)
+	}
+	commit(t, dir, "another change")
+	if _, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--commit", token, "--validate-only"); err == nil || !strings.Contains(err.Error(), "is stale") {
+		t.Errorf("error = %v, want a stale proposal", err)
+	}
+}
+
+func TestProposeCommitValidation(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--propose", "--format", "json"}, "--propose cannot be combined with --format json"},
+		{[]string{"--propose", "--transition-label"}, "--propose cannot be combined with --transition-label"},
+		{[]string{"--propose", "--strip-v"}, "--propose cannot be combined with --strip-v"},
+		{[]string{"--propose", "--update-file", "version.go", "--update-pattern", "v(.*)"}, "--propose cannot be combined with --update-file"},
+		{[]string{"--propose", "--bump-log", "bumps.jsonl"}, "--propose cannot be combined with --bump-log"},
+		{[]string{"--propose", "--write-notes"}, "--propose cannot be combined with --write-notes"},
+		{[]string{"--propose", "--github-output"}, "--propose cannot be combined with --github-output"},
+		{[]string{"--propose", "--env-file", ".env"}, "--propose cannot be combined with --env-file"},
+		{[]string{"--propose", "--format", "json", "--format-json-file", "version.json"}, "--propose cannot be combined with --format-json-file"},
+		{[]string{"--propose", "--create-tag"}, "--create-tag cannot be combined with --propose or --commit, which tags on its own"},
+		{[]string{"--commit", "v1.2.4@0", "--create-tag"}, "--create-tag cannot be combined with --propose or --commit, which tags on its own"},
+		{[]string{"--commit", "v1.2.4@0", "--resolve-conflicts", "2"}, "--resolve-conflicts requires --create-tag"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, err := runArgs(t, append([]string{"--path", dir, "--major", "1", "--minor", "2"}, tt.args...)...)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// planBumps returns the shortest sequence of bumps the strategy allows from
+// latest to target, preferring the largest legal step each time
+func planBumps(strategy IncrementStrategy, latest, target SemVer) ([]string, error) {
+	if Compare(latest, target) >= 0 {
+		return nil, fmt.Errorf("target %s is not above the latest version %s", target, latest)
+	}
+
+	var bumps []string
+	for current := latest; Compare(current, target) < 0; {
+		candidates := [][2]int{
+			{target.Major, target.Minor},
+			{current.Major + 1, 0},
+			{current.Major, current.Minor + 1},
+			{current.Major, current.Minor},
+		}
+		advanced := false
+		for _, c := range candidates {
+			next, err := strategy.Next(current, c[0], c[1])
+			if err != nil || Compare(next, target) > 0 {
+				continue
+			}
+			bumps = append(bumps, BumpKind(current, next))
+			current, advanced = next, true
+			break
+		}
+		if !advanced {
+			return nil, fmt.Errorf("target %s is unreachable from %s: stuck at %s", target, latest, current)
+		}
+	}
+	return bumps, nil
+}
+
+// runReach prints the bumps needed to get from the latest tag to --reach
+func runReach(opts Options, out io.Writer) error {
+	target, err := parseVersion(opts.Reach)
+	if err != nil {
+		return err
+	}
+	if target.Prerelease != "" {
+		return fmt.Errorf("invalid --reach %s: bumps never produce prereleases", opts.Reach)
+	}
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+	strategy, err := strategyByName(opts.Strategy)
+	if err != nil {
+		return err
+	}
+
+	tags, err := getSemverTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+	latest := SemVer{Major: tags[0].Major, Minor: tags[0].Minor, Patch: tags[0].Patch}
+	bumps, err := planBumps(strategy, latest, target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, strings.Join(bumps, ", "))
+	return nil
+}
+
+// runBumpOptions prints the next patch, minor and major versions the strategy
+// allows from the latest tag as a JSON array, for release UIs
+func runBumpOptions(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+	strategy, err := strategyByName(opts.Strategy)
+	if err != nil {
+		return err
+	}
+	tags, err := getSemverTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+
+	type bumpOption struct {
+		Bump    string `json:"bump"`
+		Version string `json:"version"`
+	}
+	latest := tags[0]
+	options := []bumpOption{}
+	for _, c := range []struct {
+		bump         string
+		major, minor int
+	}{
+		{"patch", latest.Major, latest.Minor},
+		{"minor", latest.Major, latest.Minor + 1},
+		{"major", latest.Major + 1, 0},
+	} {
+		next, err := strategy.Next(latest, c.major, c.minor)
+		if err != nil {
+			slog.Debug("bump is not allowed", "bump", c.bump, "err", err)
+			continue
+		}
+		options = append(options, bumpOption{c.bump, FormatTag(next, opts)})
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
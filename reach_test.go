@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanBumps(t *testing.T) {
+	latest := SemVer{Major: 1, Minor: 2, Patch: 3}
+	tests := []struct {
+		strategy string
+		target   SemVer
+		want     string
+		wantErr  bool
+	}{
+		{"strict", SemVer{Major: 1, Minor: 2, Patch: 5}, "patch, patch", false},
+		{"strict", SemVer{Major: 1, Minor: 4}, "minor, minor", false},
+		{"strict", SemVer{Major: 2}, "major", false},
+		{"strict", SemVer{Major: 3, Minor: 1, Patch: 1}, "major, major, minor, patch", false},
+		{"lenient", SemVer{Major: 3, Minor: 1, Patch: 1}, "major, patch", false},
+		{"strict", SemVer{Major: 1, Minor: 2, Patch: 3}, "", true},
+		{"strict", SemVer{Major: 1, Minor: 1}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.target.String(), func(t *testing.T) {
+			strategy, _ := strategyByName(tt.strategy)
+			bumps, err := planBumps(strategy, latest, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(bumps, ", "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReach(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{"v2.1.0", "major, minor\n", false},
+		{"1.2.4", "patch\n", false},
+		{"v2.0.0-rc.1", "", true},
+		{"v1.0.0", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--reach", tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		args []string
+		want string
+	}{
+		{"latest release", []string{"v1.2.3"}, nil, `[{"bump":"patch","version":"v1.2.4"},{"bump":"minor","version":"v1.3.0"},{"bump":"major","version":"v2.0.0"}]` + "\n"},
+		{"no tags", nil, nil, `[{"bump":"patch","version":"v0.0.1"},{"bump":"minor","version":"v0.1.0"},{"bump":"major","version":"v1.0.0"}]` + "\n"},
+		{"edition", []string{"v1.0.0-ce", "v1.4.0"}, []string{"--edition", "ce"}, `[{"bump":"patch","version":"v1.0.1-ce"},{"bump":"minor","version":"v1.1.0-ce"},{"bump":"major","version":"v2.0.0-ce"}]` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			if len(tt.tags) == 0 {
+				commit(t, dir, "initial")
+			}
+			got, err := runArgs(t, append([]string{"--path", dir, "--options"}, tt.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
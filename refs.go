@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// latestTagAt returns the highest semver tag reachable from ref
+func latestTagAt(ref string, opts Options) (SemVer, error) {
+	names, err := listMergedGitTags(opts.Path, ref, opts)
+	if err != nil {
+		return SemVer{}, fmt.Errorf("failed to get tags merged into %s: %w", ref, err)
+	}
+	tags, err := parseSemverTags(names, opts)
+	if err != nil {
+		return SemVer{}, err
+	}
+	return tags[0], nil
+}
+
+// runCompareRefs checks that the latest version reachable from the head ref
+// exceeds the one reachable from the base ref
+func runCompareRefs(opts Options, out io.Writer) error {
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+
+	base, err := latestTagAt(opts.BaseRef, opts)
+	if err != nil {
+		return err
+	}
+	head, err := latestTagAt(opts.HeadRef, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "base=%s head=%s\n", FormatTag(base, opts), FormatTag(head, opts))
+	if Compare(head, base) <= 0 {
+		return fmt.Errorf("head version %s does not exceed base version %s", FormatTag(head, opts), FormatTag(base, opts))
+	}
+	return nil
+}
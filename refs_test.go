@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestCompareRefs(t *testing.T) {
+	dir := newRepo(t, "v1.2.0")
+	runGit(t, dir, "branch", "stale")
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	commit(t, dir, "release v1.3.0")
+	runGit(t, dir, "tag", "v1.3.0")
+	runGit(t, dir, "checkout", "-q", "main")
+	commit(t, dir, "release v1.2.1")
+	runGit(t, dir, "tag", "v1.2.1")
+
+	tests := []struct {
+		base, head string
+		want       string
+		wantErr    bool
+	}{
+		{"main", "feature", "base=v1.2.1 head=v1.3.0\n", false},
+		{"feature", "main", "base=v1.3.0 head=v1.2.1\n", true},
+		{"stale", "main", "base=v1.2.0 head=v1.2.1\n", false},
+		{"main", "main", "base=v1.2.1 head=v1.2.1\n", true},
+		{"main", "missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.base+"..."+tt.head, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--base-ref", tt.base, "--head-ref", tt.head)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareRefsNeedsBoth(t *testing.T) {
+	for _, args := range [][]string{{"--base-ref", "main"}, {"--head-ref", "main"}} {
+		if err := validateOptions(parseArgs(t, append([]string{"--path", "."}, args...)...)); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
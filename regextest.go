@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// runTestRegex prints, for every tag, whether the proposed pattern matches it
+// and the version it would parse to
+func runTestRegex(opts Options, out io.Writer) error {
+	pattern, err := regexp.Compile(opts.TestRegex)
+	if err != nil {
+		return fmt.Errorf("invalid --test-regex pattern: %w", err)
+	}
+	if err := checkIfPathExists(opts.Path); err != nil {
+		return err
+	}
+	if err := checkIfGitRepo(opts.Path); err != nil {
+		return err
+	}
+
+	names, err := listGitTags(opts.Path, opts)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if v, ok := matchVersionRegex(pattern, name); ok {
+			fmt.Fprintf(out, "%s: match %d.%d.%d\n", name, v.Major, v.Minor, v.Patch)
+		} else {
+			fmt.Fprintf(out, "%s: no match\n", name)
+		}
+	}
+	return nil
+}
+
+// matchVersionRegex parses a tag with a pattern using the major, minor and
+// patch named groups, or else its first three groups
+func matchVersionRegex(pattern *regexp.Regexp, tag string) (SemVer, bool) {
+	matches := pattern.FindStringSubmatch(tag)
+	if matches == nil {
+		return SemVer{}, false
+	}
+	component := func(name string, position int) int {
+		i := pattern.SubexpIndex(name)
+		if i < 0 {
+			i = position
+		}
+		if i >= len(matches) {
+			return 0
+		}
+		n, _ := strconv.Atoi(matches[i])
+		return n
+	}
+	return SemVer{Major: component("major", 1), Minor: component("minor", 2), Patch: component("patch", 3)}, true
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMatchVersionRegex(t *testing.T) {
+	tests := []struct {
+		pattern, tag string
+		want         string
+		wantOK       bool
+	}{
+		{`^v(\d+)\.(\d+)\.(\d+)$`, "v1.2.3", "v1.2.3", true},
+		{`^release-(\d+)\.(\d+)\.(\d+)$`, "v1.2.3", "", false},
+		{`^(?P<patch>\d+)-(?P<minor>\d+)-(?P<major>\d+)$`, "3-2-1", "v1.2.3", true},
+		{`^v(\d+)\.(\d+)$`, "v4.5", "v4.5.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.tag, func(t *testing.T) {
+			v, ok := matchVersionRegex(regexp.MustCompile(tt.pattern), tt.tag)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && v.String() != tt.want {
+				t.Errorf("got %s, want %s", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestRegex(t *testing.T) {
+	dir := newRepo(t, "release-1.2.3", "v2.0.0")
+	got, err := runArgs(t, "--path", dir, "--test-regex", `^release-(\d+)\.(\d+)\.(\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "release-1.2.3: match 1.2.3\nv2.0.0: no match\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := runArgs(t, "--path", dir, "--test-regex", `(`); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
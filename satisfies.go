@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	constraintRegex = regexp.MustCompile(`^(>=|<=|>|<|=)?(.+)$`)
+	versionRegex    = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?$`)
+)
+
+// constraint is one comparison of a range, such as >=1.2.0
+type constraint struct {
+	op      string
+	version SemVer
+}
+
+// parseVersion reads a version such as v1.2.3 or 1.2.3-rc.1
+func parseVersion(s string) (SemVer, error) {
+	matches := versionRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return SemVer{}, fmt.Errorf("invalid version %q: must be like 1.2.3 or v1.2.3-rc.1", s)
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch, _ := strconv.Atoi(matches[3])
+	return SemVer{Major: major, Minor: minor, Patch: patch, Prerelease: matches[4]}, nil
+}
+
+// parseRange reads space-separated constraints that must all hold
+func parseRange(expr string) ([]constraint, error) {
+	var constraints []constraint
+	for _, field := range strings.Fields(expr) {
+		matches := constraintRegex.FindStringSubmatch(field)
+		v, err := parseVersion(matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid --satisfies range %q: %w", expr, err)
+		}
+		op := matches[1]
+		if op == "" {
+			op = "="
+		}
+		constraints = append(constraints, constraint{op: op, version: v})
+	}
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf("invalid --satisfies range %q: no constraints", expr)
+	}
+	return constraints, nil
+}
+
+// satisfies reports whether v meets every constraint; editions and epochs
+// are not part of the comparison
+func satisfies(v SemVer, constraints []constraint) bool {
+	v = SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Revision: v.Revision, Prerelease: v.Prerelease}
+	for _, c := range constraints {
+		cmp := Compare(v, c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSatisfies fails unless v is within the --satisfies range
+func checkSatisfies(v SemVer, opts Options) error {
+	constraints, err := parseRange(opts.Satisfies)
+	if err != nil {
+		return err
+	}
+	if !satisfies(v, constraints) {
+		return fmt.Errorf("version %s does not satisfy %q", FormatTag(v, opts), opts.Satisfies)
+	}
+	return nil
+}
+
+// runSatisfies checks a supplied version instead of a computed one
+func runSatisfies(opts Options) error {
+	v, err := parseVersion(opts.SatisfiesVersion)
+	if err != nil {
+		return err
+	}
+	return checkSatisfies(v, opts)
+}
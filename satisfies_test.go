@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		expr, version string
+		want          bool
+	}{
+		{">=1.2.0 <2.0.0", "1.2.0", true},
+		{">=1.2.0 <2.0.0", "v1.9.9", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0 <2.0.0", "1.1.9", false},
+		{">=1.2.0 <2.0.0", "2.0.0-rc.1", true},
+		{">1.2.0", "1.2.0", false},
+		{"<=1.2.0", "1.2.0", true},
+		{"1.2.0", "v1.2.0", true},
+		{"=1.2.0", "1.2.1", false},
+		{">=1.0.0-alpha.1", "1.0.0-alpha.beta", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+"/"+tt.version, func(t *testing.T) {
+			constraints, err := parseRange(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := parseVersion(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := satisfies(v, constraints); got != tt.want {
+				t.Errorf("satisfies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, expr := range []string{"", "  ", ">=1.2", "~1.2.0", ">=1.2.0 <two"} {
+		if _, err := parseRange(expr); err == nil {
+			t.Errorf("parseRange(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestSatisfiesFlag(t *testing.T) {
+	dir := newRepo(t, "v1.9.9")
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--path", dir, "--major", "1", "--minor", "9"}, ""},
+		{[]string{"--path", dir, "--major", "2", "--minor", "0"}, `version v2.0.0 does not satisfy ">=1.2.0 <2.0.0"`},
+		{[]string{"--satisfies-version", "1.5.0"}, ""},
+		{[]string{"--satisfies-version", "2.5.0"}, `version v2.5.0 does not satisfy`},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, err := runArgs(t, append([]string{"--satisfies", ">=1.2.0 <2.0.0"}, tt.args...)...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var identifierRegex = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// SemVer represents a semantic versioning tag
+type SemVer struct {
+	// Epoch orders versions across a versioning reset, as in 1!v2.0.0; 0 is not rendered
+	// Git does not allow ':' in tag names, so the epoch uses the PEP 440 '!' separator
+	Epoch int
+	// Prefix precedes the numeric components; empty means "v"
+	Prefix string
+	Major  int
+	Minor  int
+	Patch  int
+	// Revision is the fourth component of four-component versions
+	Revision int
+	// Components is the number of numeric components rendered: 2, 3 or 4 (0 means 3)
+	Components int
+	// Edition is a fixed suffix identifying a tag series, such as "ce" in v1.2.3-ce
+	Edition string
+	// Prerelease holds the dot-separated prerelease identifiers, without the leading hyphen
+	Prerelease string
+	// Suffix is opaque trailing text such as "_linux_amd64", kept as is and ignored for ordering
+	Suffix string
+	// Build is CI metadata after an underscore, such as "build456" in v1.2.3_build456; ignored for ordering
+	Build string
+	// Raw is the tag name the version was parsed from, empty for computed versions
+	Raw string
+}
+
+func (v SemVer) String() string {
+	s := v.Prefix
+	if s == "" {
+		s = "v"
+	}
+	switch v.Components {
+	case 2:
+		s += fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	case 4:
+		s += fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Revision)
+	default:
+		s += fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	}
+	if v.Epoch > 0 {
+		s = fmt.Sprintf("%d!%s", v.Epoch, s)
+	}
+	if v.Edition != "" {
+		s += "-" + v.Edition
+	}
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "_" + v.Build
+	}
+	return s + v.Suffix
+}
+
+// Zero returns the v0.0.0 starting point used when a repository has no tags
+func Zero() SemVer {
+	return SemVer{Major: 0, Minor: 0, Patch: 0}
+}
+
+// Validate checks that the version components are non-negative and that the
+// edition and prerelease are made of valid SemVer identifiers
+func (v SemVer) Validate() error {
+	if v.Epoch < 0 {
+		return fmt.Errorf("invalid epoch %d: must not be negative", v.Epoch)
+	}
+	if v.Major < 0 || v.Minor < 0 || v.Patch < 0 || v.Revision < 0 {
+		return fmt.Errorf("invalid version v%d.%d.%d: components must not be negative", v.Major, v.Minor, v.Patch)
+	}
+	if v.Edition != "" && !identifierRegex.MatchString(v.Edition) {
+		return fmt.Errorf("invalid edition %q: only alphanumerics and hyphens are allowed", v.Edition)
+	}
+	if v.Prerelease == "" {
+		return nil
+	}
+	for _, id := range strings.Split(v.Prerelease, ".") {
+		if !identifierRegex.MatchString(id) {
+			return fmt.Errorf("invalid prerelease %q: identifiers must be non-empty alphanumerics and hyphens", v.Prerelease)
+		}
+		if len(id) > 1 && id[0] == '0' && strings.Trim(id, "0123456789") == "" {
+			return fmt.Errorf("invalid prerelease %q: numeric identifiers must not have leading zeros", v.Prerelease)
+		}
+	}
+	return nil
+}
+
+// Compare returns -1, 0 or 1 depending on whether a has lower, equal or higher
+// precedence than b. The epoch dominates, and a version without prerelease
+// ranks above one with it.
+func Compare(a, b SemVer) int {
+	if c := cmp.Compare(a.Epoch, b.Epoch); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Revision, b.Revision); c != 0 {
+		return c
+	}
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease orders prerelease strings per SemVer §11: identifiers are
+// compared left to right, numeric ones numerically and below alphanumeric
+// ones, which compare lexically; a longer list wins when all else is equal
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// bumpInputs returns the major and minor inputs that apply a patch, minor or
+// major bump to latestTag. With zeroVer, a major bump of a 0.x version bumps
+// the minor instead, as breaking changes do during initial development.
+func bumpInputs(latestTag SemVer, bump string, zeroVer bool) (int, int) {
+	switch bump {
+	case "minor":
+		return latestTag.Major, latestTag.Minor + 1
+	case "major":
+		if zeroVer && latestTag.Major == 0 {
+			return 0, latestTag.Minor + 1
+		}
+		return latestTag.Major + 1, 0
+	}
+	return latestTag.Major, latestTag.Minor
+}
+
+// Equal reports whether v and o have the same precedence within the same
+// edition; the raw tag name does not matter
+func (v SemVer) Equal(o SemVer) bool {
+	return v.Edition == o.Edition && Compare(v, o) == 0
+}
+
+// BumpKind names the most significant component that changed from one
+// version to the next: "major", "minor", "patch" or "none"
+func BumpKind(from, to SemVer) string {
+	switch {
+	case from.Major != to.Major:
+		return "major"
+	case from.Minor != to.Minor:
+		return "minor"
+	case from.Patch != to.Patch, from.Revision != to.Revision:
+		return "patch"
+	}
+	return "none"
+}
+
+// BumpMajor returns the next major version, resetting minor and lower
+// components; the prefix, edition, epoch and suffix are kept. As with npm,
+// the next major of a major prerelease such as v2.0.0-rc.1 is its release.
+func (v SemVer) BumpMajor() SemVer {
+	if v.Prerelease == "" || v.Minor != 0 || v.Patch != 0 || v.Revision != 0 {
+		v.Major++
+	}
+	v.Minor, v.Patch, v.Revision = 0, 0, 0
+	return v.release()
+}
+
+// BumpMinor returns the next minor version, resetting patch and revision. The
+// next minor of a minor prerelease such as v1.3.0-rc.2 is its release.
+func (v SemVer) BumpMinor() SemVer {
+	if v.Prerelease == "" || v.Patch != 0 || v.Revision != 0 {
+		v.Minor++
+	}
+	v.Patch, v.Revision = 0, 0
+	return v.release()
+}
+
+// BumpPatch returns the next patch version, resetting the revision and
+// dropping the prerelease and build metadata. The next patch of a prerelease
+// is its release, as in v1.3.0-rc.2 to v1.3.0.
+func (v SemVer) BumpPatch() SemVer {
+	if v.Prerelease == "" {
+		v.Patch++
+		v.Revision = 0
+	}
+	return v.release()
+}
+
+// release drops the prerelease, the build metadata and the raw tag name
+func (v SemVer) release() SemVer {
+	v.Prerelease = ""
+	v.Build = ""
+	v.Raw = ""
+	return v
+}
+
+// applyComponents moves a patch bump to the least significant component of
+// two- and four-component versions
+func applyComponents(latestTag, next SemVer, components int) SemVer {
+	patchBump := BumpKind(latestTag, next) == "patch"
+	switch components {
+	case 2:
+		if patchBump {
+			next.Minor++
+		}
+		next.Patch = 0
+	case 4:
+		if patchBump {
+			next.Patch = latestTag.Patch
+			next.Revision = latestTag.Revision + 1
+		}
+	}
+	next.Components = components
+	return next
+}
+
+// calculateNextVersion applies the strategy; a skip to one of the allowed
+// versions is accepted anyway, as an approved planned jump
+func calculateNextVersion(strategy IncrementStrategy, latestTag SemVer, majorInput, minorInput int, allowed []SemVer) (SemVer, error) {
+	if err := (SemVer{Major: majorInput, Minor: minorInput}).Validate(); err != nil {
+		return SemVer{}, err
+	}
+	next, err := strategy.Next(latestTag, majorInput, minorInput)
+	if errors.Is(err, ErrSkip) {
+		jump := SemVer{Major: majorInput, Minor: minorInput}
+		for _, v := range allowed {
+			if Compare(v, jump) == 0 {
+				slog.Info("allowing skip to approved version", "version", jump.String())
+				return jump, nil
+			}
+		}
+	}
+	return next, err
+}
@@ -0,0 +1,167 @@
+package main
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       SemVer
+		wantErr bool
+	}{
+		{"zero", Zero(), false},
+		{"release", SemVer{Major: 1, Minor: 2, Patch: 3}, false},
+		{"prerelease", SemVer{Major: 1, Prerelease: "rc.1.alpha-2"}, false},
+		{"edition", SemVer{Major: 1, Edition: "ce"}, false},
+		{"negative major", SemVer{Major: -1}, true},
+		{"negative revision", SemVer{Revision: -1}, true},
+		{"negative epoch", SemVer{Epoch: -1}, true},
+		{"bad edition", SemVer{Edition: "c e"}, true},
+		{"empty identifier", SemVer{Prerelease: "rc..1"}, true},
+		{"leading zero", SemVer{Prerelease: "rc.01"}, true},
+		{"zero identifier", SemVer{Prerelease: "rc.0"}, false},
+		{"alphanumeric leading zero", SemVer{Prerelease: "0a"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.v.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b SemVer
+		want int
+	}{
+		{"equal", SemVer{Major: 1, Minor: 2, Patch: 3}, SemVer{Major: 1, Minor: 2, Patch: 3}, 0},
+		{"major", SemVer{Major: 2}, SemVer{Major: 1, Minor: 9, Patch: 9}, 1},
+		{"minor", SemVer{Major: 1, Minor: 2}, SemVer{Major: 1, Minor: 10}, -1},
+		{"patch", SemVer{Patch: 10}, SemVer{Patch: 9}, 1},
+		{"revision", SemVer{Patch: 1, Revision: 2}, SemVer{Patch: 1, Revision: 1}, 1},
+		{"epoch dominates", SemVer{Epoch: 1, Major: 1}, SemVer{Major: 9}, 1},
+		{"release above prerelease", SemVer{Major: 1}, SemVer{Major: 1, Prerelease: "rc.1"}, 1},
+		{"raw ignored", SemVer{Major: 1, Raw: "v1.0.0"}, SemVer{Major: 1, Raw: "release-1.0.0"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare() = %d, want %d", got, tt.want)
+			}
+			if got := Compare(tt.b, tt.a); got != -tt.want {
+				t.Errorf("reversed Compare() = %d, want %d", got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b SemVer
+		want bool
+	}{
+		{"same", SemVer{Major: 1, Minor: 2, Patch: 3}, SemVer{Major: 1, Minor: 2, Patch: 3}, true},
+		{"build ignored", SemVer{Major: 1, Build: "build456"}, SemVer{Major: 1, Build: "build789"}, true},
+		{"suffix ignored", SemVer{Major: 1, Suffix: "_linux_amd64"}, SemVer{Major: 1}, true},
+		{"raw ignored", SemVer{Major: 1, Raw: "v1.0.0"}, SemVer{Major: 1}, true},
+		{"patch differs", SemVer{Major: 1, Patch: 1}, SemVer{Major: 1}, false},
+		{"prerelease differs", SemVer{Major: 1, Prerelease: "rc.1"}, SemVer{Major: 1}, false},
+		{"edition differs", SemVer{Major: 1, Edition: "ce"}, SemVer{Major: 1, Edition: "ee"}, false},
+		{"epoch differs", SemVer{Epoch: 1, Major: 1}, SemVer{Major: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Equal(tt.a); got != tt.want {
+				t.Errorf("reversed Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparePrerelease(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"alpha", "alpha.1", -1},
+		{"alpha.1", "alpha.beta", -1},
+		{"alpha.beta", "beta", -1},
+		{"beta", "beta.2", -1},
+		{"beta.2", "beta.11", -1},
+		{"beta.11", "rc.1", -1},
+		{"rc.1", "rc.1.1", -1},
+		{"rc.1", "rc.1", 0},
+		{"1", "a", -1},
+		{"rc.1", "rc-1", -1},
+		{"99999999999999999999", "a", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := comparePrerelease(tt.a, tt.b); got != tt.want {
+				t.Errorf("comparePrerelease() = %d, want %d", got, tt.want)
+			}
+			if got := comparePrerelease(tt.b, tt.a); got != -tt.want {
+				t.Errorf("reversed comparePrerelease() = %d, want %d", got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrereleaseOrder(t *testing.T) {
+	// The SemVer §11 example, shuffled
+	names := []string{"v1.0.0-rc.1", "v1.0.0-alpha.beta", "v1.0.0", "v1.0.0-beta.11", "v1.0.0-alpha", "v1.0.0-beta", "v1.0.0-alpha.1", "v1.0.0-beta.2"}
+	tags, err := parseSemverTags(names, Options{Prereleases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "v1.0.0,v1.0.0-rc.1,v1.0.0-beta.11,v1.0.0-beta.2,v1.0.0-beta,v1.0.0-alpha.beta,v1.0.0-alpha.1,v1.0.0-alpha"
+	if got := rawNames(tags); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	for _, tag := range tags {
+		if tag.String() != tag.Raw {
+			t.Errorf("%s parsed as %+v", tag.Raw, tag)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	base := SemVer{Prefix: "api-v", Major: 1, Minor: 2, Patch: 3, Revision: 4, Edition: "ce", Build: "build7", Suffix: "_linux", Raw: "api-v1.2.3-ce_build7_linux"}
+	rc := SemVer{Major: 1, Minor: 3, Prerelease: "rc.2", Raw: "v1.3.0-rc.2"}
+	patchRC := SemVer{Major: 1, Minor: 3, Patch: 1, Prerelease: "rc.1", Build: "b5", Raw: "v1.3.1-rc.1+b5"}
+	majorRC := SemVer{Major: 2, Prerelease: "beta.1", Raw: "v2.0.0-beta.1"}
+	tests := []struct {
+		name string
+		got  SemVer
+		want SemVer
+	}{
+		{"patch", base.BumpPatch(), SemVer{Prefix: "api-v", Major: 1, Minor: 2, Patch: 4, Edition: "ce", Suffix: "_linux"}},
+		{"minor", base.BumpMinor(), SemVer{Prefix: "api-v", Major: 1, Minor: 3, Edition: "ce", Suffix: "_linux"}},
+		{"major", base.BumpMajor(), SemVer{Prefix: "api-v", Major: 2, Edition: "ce", Suffix: "_linux"}},
+		{"patch of prerelease", rc.BumpPatch(), SemVer{Major: 1, Minor: 3}},
+		{"minor of minor prerelease", rc.BumpMinor(), SemVer{Major: 1, Minor: 3}},
+		{"major of minor prerelease", rc.BumpMajor(), SemVer{Major: 2}},
+		{"patch of patch prerelease", patchRC.BumpPatch(), SemVer{Major: 1, Minor: 3, Patch: 1}},
+		{"minor of patch prerelease", patchRC.BumpMinor(), SemVer{Major: 1, Minor: 4}},
+		{"major of patch prerelease", patchRC.BumpMajor(), SemVer{Major: 2}},
+		{"patch of major prerelease", majorRC.BumpPatch(), SemVer{Major: 2}},
+		{"minor of major prerelease", majorRC.BumpMinor(), SemVer{Major: 2}},
+		{"major of major prerelease", majorRC.BumpMajor(), SemVer{Major: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+	if base.Raw == "" {
+		t.Error("bumping modified the receiver")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+)
+
+// runServe answers "<path> <major> [<minor>]" lines on a Unix socket with
+// the next version, or "error: <message>", until interrupted
+func runServe(opts Options) error {
+	// A socket left behind by a previous run would make Listen fail
+	if err := os.Remove(opts.Serve); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket %s: %w", opts.Serve, err)
+	}
+	listener, err := net.Listen("unix", opts.Serve)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.Serve, err)
+	}
+	defer os.Remove(opts.Serve)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	slog.Info("serving versions", "socket", opts.Serve)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go serveConn(conn, opts)
+	}
+}
+
+func serveConn(conn net.Conn, opts Options) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintln(conn, serveRequest(scanner.Text(), opts)); err != nil {
+			return
+		}
+	}
+}
+
+// serveRequest computes the response line for one request line
+func serveRequest(line string, opts Options) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return "error: expected <path> <major> [<minor>]"
+	}
+	opts.Path, opts.Minor = fields[0], -1
+	var err error
+	if opts.Major, err = strconv.Atoi(fields[1]); err != nil {
+		return "error: invalid major " + strconv.Quote(fields[1])
+	}
+	if len(fields) == 3 {
+		if opts.Minor, err = strconv.Atoi(fields[2]); err != nil {
+			return "error: invalid minor " + strconv.Quote(fields[2])
+		}
+	}
+
+	// Each request is checked like a command line with the same inputs
+	opts.Serve = ""
+	if err := validateOptions(opts); err != nil {
+		return "error: " + err.Error()
+	}
+
+	latestTag, nextVersion, err := computeNextVersion(opts.Path, opts)
+	if err != nil {
+		if errors.Is(err, errNoTags) {
+			return ""
+		}
+		return "error: " + strings.ReplaceAll(err.Error(), "\n", " ")
+	}
+	return formatVersion(latestTag, nextVersion, opts)
+}
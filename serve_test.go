@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	untagged := newRepo(t)
+	commit(t, untagged, "initial")
+	socket := filepath.Join(t.TempDir(), "semver.sock")
+	opts := parseArgs(t, "--serve", socket, "--max-allowed-major", "5", "--no-default-start")
+	done := make(chan error)
+	go func() { done <- runServe(opts) }()
+
+	var conn net.Conn
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		if conn, err = net.Dial("unix", socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		request string
+		want    string
+	}{
+		{dir + " 1 2", "v1.2.4"},
+		{dir + " 1 3", "v1.3.0"},
+		{dir + " 1", "v1.2.4"},
+		{dir + " 1 5", "error: invalid minor version: you cannot skip minor versions (latest: 2, input: 5)"},
+		{dir + " 9 0", "error: major 9 is above --max-allowed-major 5; check the input for a typo"},
+		{dir + " one", `error: invalid major "one"`},
+		{dir, "error: expected <path> <major> [<minor>]"},
+		{untagged + " 0 1", ""},
+	}
+	reader := bufio.NewReader(conn)
+	for _, tt := range tests {
+		t.Run(tt.request, func(t *testing.T) {
+			if _, err := fmt.Fprintln(conn, tt.request); err != nil {
+				t.Fatal(err)
+			}
+			got, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want+"\n" {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// An interrupt stops the server and removes the socket
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("--serve did not stop on interrupt")
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("socket was not removed: %v", err)
+	}
+}
+
+func TestServeRejectsInteractiveOptions(t *testing.T) {
+	for _, flag := range [][]string{{"--confirm-major"}, {"--pre-bump-script", "check.sh"}} {
+		if err := validateOptions(parseArgs(t, append([]string{"--serve", "semver.sock"}, flag...)...)); err == nil {
+			t.Errorf("%v: expected an error", flag)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by strategies are classified as one of these, so callers
+// can tell them apart with errors.Is
+var (
+	ErrDowngrade = errors.New("version downgrade")
+	ErrSkip      = errors.New("version skip")
+)
+
+// versionError keeps the detailed message while unwrapping to its class
+type versionError struct {
+	class error
+	msg   string
+}
+
+func (e *versionError) Error() string { return e.msg }
+func (e *versionError) Unwrap() error { return e.class }
+
+func downgradeErrorf(format string, args ...any) error {
+	return &versionError{class: ErrDowngrade, msg: fmt.Sprintf(format, args...)}
+}
+
+func skipErrorf(format string, args ...any) error {
+	return &versionError{class: ErrSkip, msg: fmt.Sprintf(format, args...)}
+}
+
+// IncrementStrategy decides the next version from the latest tag and the
+// requested major and minor
+type IncrementStrategy interface {
+	Next(latestTag SemVer, majorInput, minorInput int) (SemVer, error)
+}
+
+// strategies maps --strategy names to their implementation
+var strategies = map[string]IncrementStrategy{
+	"strict":  StrictStrategy{},
+	"lenient": LenientStrategy{},
+}
+
+func strategyByName(name string) (IncrementStrategy, error) {
+	if name == "" {
+		return StrictStrategy{}, nil
+	}
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid --strategy %q: must be strict or lenient", name)
+	}
+	return strategy, nil
+}
+
+// StrictStrategy only allows the next patch, the next minor or the next major
+type StrictStrategy struct{}
+
+func (StrictStrategy) Next(latestTag SemVer, majorInput, minorInput int) (SemVer, error) {
+	if majorInput < latestTag.Major {
+		return SemVer{}, downgradeErrorf("invalid major version: input major (%d) cannot be less than the latest major version (%d)", majorInput, latestTag.Major)
+	}
+	if majorInput == latestTag.Major {
+		if minorInput < latestTag.Minor {
+			return SemVer{}, downgradeErrorf("invalid minor version: input minor (%d) cannot be less than the latest minor version (%d)", minorInput, latestTag.Minor)
+		}
+		if minorInput == latestTag.Minor {
+			return latestTag.BumpPatch(), nil
+		} else if minorInput == latestTag.Minor+1 {
+			// the inputs name the version after the release of a prerelease
+			return latestTag.release().BumpMinor(), nil
+		}
+		return SemVer{}, skipErrorf("invalid minor version: you cannot skip minor versions (latest: %d, input: %d)", latestTag.Minor, minorInput)
+	}
+
+	if majorInput == latestTag.Major+1 && minorInput == 0 {
+		return latestTag.release().BumpMajor(), nil
+	}
+
+	return SemVer{}, skipErrorf("invalid version: skipping versions is not allowed (latest: %s, input: v%d.%d.x)", latestTag, majorInput, minorInput)
+}
+
+// LenientStrategy allows skipping versions as long as the result does not go
+// backwards
+type LenientStrategy struct{}
+
+func (LenientStrategy) Next(latestTag SemVer, majorInput, minorInput int) (SemVer, error) {
+	if majorInput < latestTag.Major || (majorInput == latestTag.Major && minorInput < latestTag.Minor) {
+		return SemVer{}, downgradeErrorf("invalid version: input v%d.%d.x is lower than the latest version %s", majorInput, minorInput, latestTag)
+	}
+	if majorInput == latestTag.Major && minorInput == latestTag.Minor {
+		return latestTag.BumpPatch(), nil
+	}
+	return SemVer{Major: majorInput, Minor: minorInput, Patch: 0}, nil
+}
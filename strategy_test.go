@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStrategies(t *testing.T) {
+	latest := SemVer{Major: 1, Minor: 2, Patch: 3}
+	tests := []struct {
+		strategy     string
+		major, minor int
+		want         string
+		wantErr      error
+	}{
+		{"strict", 1, 2, "v1.2.4", nil},
+		{"strict", 1, 3, "v1.3.0", nil},
+		{"strict", 2, 0, "v2.0.0", nil},
+		{"strict", 1, 5, "", ErrSkip},
+		{"strict", 3, 0, "", ErrSkip},
+		{"strict", 2, 1, "", ErrSkip},
+		{"strict", 1, 1, "", ErrDowngrade},
+		{"strict", 0, 9, "", ErrDowngrade},
+		{"lenient", 1, 2, "v1.2.4", nil},
+		{"lenient", 1, 5, "v1.5.0", nil},
+		{"lenient", 3, 4, "v3.4.0", nil},
+		{"lenient", 1, 1, "", ErrDowngrade},
+		{"lenient", 0, 9, "", ErrDowngrade},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+SemVer{Major: tt.major, Minor: tt.minor}.String(), func(t *testing.T) {
+			strategy, err := strategyByName(tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := strategy.Next(latest, tt.major, tt.minor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictStrategyFromPrerelease(t *testing.T) {
+	tests := []struct {
+		latest       SemVer
+		major, minor int
+		want         string
+	}{
+		{SemVer{Major: 1, Minor: 3, Prerelease: "rc.1"}, 1, 3, "v1.3.0"},
+		{SemVer{Major: 1, Minor: 3, Prerelease: "rc.1"}, 1, 4, "v1.4.0"},
+		{SemVer{Major: 1, Minor: 3, Prerelease: "rc.1"}, 2, 0, "v2.0.0"},
+		{SemVer{Major: 2, Prerelease: "beta.1"}, 2, 0, "v2.0.0"},
+		{SemVer{Major: 2, Prerelease: "beta.1"}, 2, 1, "v2.1.0"},
+		{SemVer{Major: 2, Prerelease: "beta.1"}, 3, 0, "v3.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latest.String()+"/"+SemVer{Major: tt.major, Minor: tt.minor}.String(), func(t *testing.T) {
+			got, err := StrictStrategy{}.Next(tt.latest, tt.major, tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrategyByName(t *testing.T) {
+	if s, err := strategyByName(""); err != nil || s != (StrictStrategy{}) {
+		t.Errorf("default strategy = %v, %v; want strict", s, err)
+	}
+	if _, err := strategyByName("loose"); err == nil {
+		t.Error("unknown strategy was accepted")
+	}
+}
+
+func TestAllowVersion(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{[]string{"--major", "3", "--minor", "0", "--allow-version", "3.0.0"}, "v3.0.0", false},
+		{[]string{"--major", "1", "--minor", "5", "--allow-version", "v2.0.0", "--allow-version", "v1.5.0"}, "v1.5.0", false},
+		{[]string{"--major", "3", "--minor", "0", "--allow-version", "4.0.0"}, "", true},
+		{[]string{"--major", "1", "--minor", "1", "--allow-version", "1.1.0"}, "", true},
+		{[]string{"--major", "3", "--minor", "0", "--allow-version", "3.0.1"}, "", true},
+		{[]string{"--major", "3", "--minor", "0", "--allow-version", "3.0.0-rc.1"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipPatchZero(t *testing.T) {
+	dir := newRepo(t, "v1.2.1")
+	tests := []struct {
+		major, minor string
+		want         string
+	}{
+		{"1", "2", "v1.2.2"},
+		{"1", "3", "v1.3.1"},
+		{"2", "0", "v2.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--skip-patch-zero", "--major", tt.major, "--minor", tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
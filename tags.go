@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// getSemverTags returns the semver tags from the configured source, highest
+// first, falling back to Zero when none match
+func getSemverTags(path string, opts Options) ([]SemVer, error) {
+	names, err := getTagNames(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return parseSemverTags(names, opts)
+}
+
+// getTagNames returns the raw tag names from the configured source
+func getTagNames(path string, opts Options) ([]string, error) {
+	var names []string
+	var err error
+	switch {
+	case opts.TagsFile != "":
+		names, err = readTagsFile(opts.TagsFile)
+	case opts.GitHubRepo != "":
+		names, err = fetchGitHubTags(opts.GitHubRepo, opts.GitHubToken)
+	default:
+		names, err = listGitTags(path, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxTags > 0 {
+		if opts.externalTags() {
+			// Git and go-git list their tags in this order already
+			slices.SortFunc(names, func(a, b string) int { return compareVersionNames(b, a) })
+		}
+		if len(names) > opts.MaxTags {
+			// Only the first N names by version sort are parsed
+			names = names[:opts.MaxTags]
+		}
+	}
+	if opts.ExcludeHeadTag {
+		headTags, err := listHeadTags(path)
+		if err != nil {
+			return nil, err
+		}
+		names = slices.DeleteFunc(names, func(name string) bool {
+			return slices.Contains(headTags, name)
+		})
+	}
+	if opts.ReachableOnly {
+		names = slices.DeleteFunc(names, func(name string) bool {
+			return !isReachable(path, "refs/tags/"+name)
+		})
+	}
+	return names, nil
+}
+
+// readTagsFile reads one tag name per line, skipping blank lines
+func readTagsFile(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --tags-file: %w", err)
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func parseSemverTags(names []string, opts Options) ([]SemVer, error) {
+	var ignoreRegex *regexp.Regexp
+	if opts.TagIgnore != "" {
+		var err error
+		if ignoreRegex, err = regexp.Compile(opts.TagIgnore); err != nil {
+			return nil, fmt.Errorf("invalid --tag-ignore pattern: %w", err)
+		}
+	}
+
+	namespacePrefix := ""
+	if opts.TagNamespace != "" {
+		namespacePrefix = strings.Trim(opts.TagNamespace, "/") + "/"
+	}
+
+	suffix := ""
+	if opts.Edition != "" {
+		suffix = "-" + regexp.QuoteMeta(opts.Edition)
+	}
+	epoch := ""
+	if opts.EpochAware {
+		epoch = `(?:(\d+)!)?`
+	}
+	canonicalPrefix := "v"
+	if opts.Prefix != "" {
+		canonicalPrefix = opts.Prefix
+	}
+	prefix := regexp.QuoteMeta(canonicalPrefix)
+	if opts.PrefixCase == "upper" || opts.PrefixCase == "lower" {
+		// Tags created from the rendered output carry the changed case
+		prefix = "(?i:" + prefix + ")"
+	}
+	core := prefix + `(?P<major>\d+)\.(?P<minor>\d+)\.(?P<patch>\d+)`
+	switch opts.Components {
+	case 2:
+		core = prefix + `(?P<major>\d+)\.(?P<minor>\d+)`
+	case 4:
+		core += `\.(?P<revision>\d+)`
+	}
+	if opts.readsPrereleases() {
+		// Prerelease identifiers follow the edition, as rendered by String
+		suffix += `(?:-(?P<prerelease>[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?`
+	}
+	if opts.UnderscoreBuild {
+		suffix += `(?:_(?P<build>[0-9A-Za-z.-]+))?`
+	}
+	if opts.KeepSuffix {
+		suffix += `(?P<suffix>_.*)?`
+	}
+	semverRegex := regexp.MustCompile(`^` + epoch + core + suffix + `$`)
+	var semverTags []SemVer
+	contained := 0
+
+	for _, raw := range names {
+		tag := strings.TrimPrefix(raw, namespacePrefix)
+		for _, strip := range opts.StripPrefix {
+			if rest, ok := strings.CutPrefix(tag, strip); ok {
+				// Unify the series under the canonical prefix
+				tag = canonicalPrefix + rest
+				break
+			}
+		}
+		if !containsTag(tag, opts) {
+			continue
+		}
+		contained++
+		if ignoreRegex != nil && ignoreRegex.MatchString(tag) {
+			slog.Debug("ignoring tag", "tag", tag)
+			continue
+		}
+		if matches := semverRegex.FindStringSubmatch(tag); matches != nil {
+			component := func(name string) int {
+				if i := semverRegex.SubexpIndex(name); i >= 0 {
+					n, _ := strconv.Atoi(matches[i])
+					return n
+				}
+				return 0
+			}
+			v := SemVer{
+				Major:      component("major"),
+				Minor:      component("minor"),
+				Patch:      component("patch"),
+				Revision:   component("revision"),
+				Components: opts.Components,
+				Prefix:     opts.Prefix,
+				Edition:    opts.Edition,
+				Raw:        raw,
+			}
+			if i := semverRegex.SubexpIndex("prerelease"); i >= 0 {
+				v.Prerelease = matches[i]
+			}
+			if err := v.Validate(); err != nil {
+				slog.Debug("ignoring tag", "tag", tag, "error", err)
+				continue
+			}
+			if i := semverRegex.SubexpIndex("build"); i >= 0 {
+				v.Build = matches[i]
+			}
+			if i := semverRegex.SubexpIndex("suffix"); i >= 0 {
+				v.Suffix = matches[i]
+			}
+			if opts.EpochAware && matches[1] != "" {
+				v.Epoch, _ = strconv.Atoi(matches[1])
+			}
+			semverTags = append(semverTags, v)
+		}
+	}
+
+	slog.Debug("parsed semver tags", "tags", len(names), "semver", len(semverTags))
+	if len(semverTags) == 0 && opts.TagContains != "" && contained > 0 {
+		slog.Warn("tags match --tag-contains but none is a semver tag of the configured format", "contains", opts.TagContains, "tags", contained)
+	}
+	if len(semverTags) == 0 {
+		// No existing semver tags found; start from v0.0.0
+		start := Zero()
+		start.Prefix, start.Components, start.Edition = opts.Prefix, opts.Components, opts.Edition
+		semverTags = append(semverTags, start)
+	}
+
+	sort.Slice(semverTags, func(i, j int) bool {
+		return Compare(semverTags[i], semverTags[j]) > 0
+	})
+	warnDuplicateTags(semverTags)
+
+	return semverTags, nil
+}
+
+// warnDuplicateTags warns about distinct tag names that parse to the same
+// version, which make the baseline ambiguous. Tags told apart by their
+// --keep-suffix suffix or build metadata are intended and not reported.
+func warnDuplicateTags(sorted []SemVer) {
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		raws := []string{sorted[i].Raw}
+		for ; j < len(sorted) && Compare(sorted[i], sorted[j]) == 0; j++ {
+			if sorted[j].Suffix == sorted[i].Suffix && sorted[j].Build == sorted[i].Build {
+				raws = append(raws, sorted[j].Raw)
+			}
+		}
+		if len(raws) > 1 {
+			slog.Warn("several tags parse to the same version", "version", sorted[i].String(), "tags", strings.Join(raws, ","))
+		}
+		i = j
+	}
+}
+
+func containsTag(tag string, opts Options) bool {
+	if opts.TagContains == "" {
+		return true
+	}
+	if opts.TagContainsIgnoreCase {
+		return strings.Contains(strings.ToLower(tag), strings.ToLower(opts.TagContains))
+	}
+	return strings.Contains(tag, opts.TagContains)
+}
+
+// compareVersionNames compares tag names like git's version sort, ordering
+// runs of digits by value
+func compareVersionNames(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, ra := splitDigits(a)
+			nb, rb := splitDigits(b)
+			na, nb = strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(na) != len(nb) {
+				return len(na) - len(nb)
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = ra, rb
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
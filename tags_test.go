@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// rawNames returns the tag names of parsed tags, highest first
+func rawNames(tags []SemVer) string {
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Raw)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestParseSemverTagsEdition(t *testing.T) {
+	names := []string{"v1.2.0-ce", "v1.3.0-ee", "v1.2.1", "v1.2.5-ce", "v1.4.0-ce-rc.1"}
+	tests := []struct {
+		edition string
+		want    string
+	}{
+		{"ce", "v1.4.0-ce-rc.1,v1.2.5-ce,v1.2.0-ce"},
+		{"ee", "v1.3.0-ee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.edition, func(t *testing.T) {
+			tags, err := parseSemverTags(names, Options{Edition: tt.edition, Prereleases: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			for _, tag := range tags {
+				if tag.Edition != tt.edition {
+					t.Errorf("%s has edition %q", tag.Raw, tag.Edition)
+				}
+			}
+		})
+	}
+}
+
+func TestEditionNextVersion(t *testing.T) {
+	dir := newRepo(t, "v1.2.0-ce", "v1.3.0-ee", "v1.2.1")
+	tests := []struct {
+		edition string
+		minor   string
+		want    string
+	}{
+		{"ce", "2", "v1.2.1-ce"},
+		{"ee", "3", "v1.3.1-ee"},
+		{"ee", "4", "v1.4.0-ee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.edition+"/"+tt.minor, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--edition", tt.edition, "--major", "1", "--minor", tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxTags(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	dir := newRepo(t, "v1.0.0", "v1.2.0", "latest", "v1.9.3", "v1.10.0")
+	tagsFile := writeFile(t, "tags.txt", "v1.0.0\nv1.10.0\nv1.2.0\nv1.9.3\n")
+	tests := []struct {
+		name   string
+		opts   Options
+		names  string
+		parsed int
+		want   string
+	}{
+		{"git sort", Options{MaxTags: 2}, "v1.10.0,v1.9.3", 2, "v1.10.0,v1.9.3"},
+		{"other tags take slots", Options{MaxTags: 3, TagIgnore: `^v1\.9\.`}, "v1.10.0,v1.9.3,v1.2.0", 3, "v1.10.0,v1.2.0"},
+		{"tags file", Options{MaxTags: 3, TagsFile: tagsFile}, "v1.10.0,v1.9.3,v1.2.0", 3, "v1.10.0,v1.9.3,v1.2.0"},
+		{"unlimited", Options{}, "", 5, "v1.10.0,v1.9.3,v1.2.0,v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := getTagNames(dir, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(names, ","); tt.names != "" && got != tt.names {
+				t.Errorf("names = %s, want %s", got, tt.names)
+			}
+
+			var buf bytes.Buffer
+			if err := setupLogger(&buf, "json", "debug"); err != nil {
+				t.Fatal(err)
+			}
+			tags, err := getSemverTags(dir, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			var record struct {
+				Msg  string `json:"msg"`
+				Tags int    `json:"tags"`
+			}
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if json.Unmarshal([]byte(line), &record) == nil && record.Msg == "parsed semver tags" {
+					break
+				}
+			}
+			if record.Tags != tt.parsed {
+				t.Errorf("parsed %d tags, want %d", record.Tags, tt.parsed)
+			}
+		})
+	}
+}
+
+func TestCompareVersionNames(t *testing.T) {
+	names := []string{"v1.2.0", "v1.10.0", "v1.9.3", "v01.11.0", "latest", "v1.2.0-rc.1"}
+	slices.SortFunc(names, func(a, b string) int { return compareVersionNames(b, a) })
+	if got, want := strings.Join(names, ","), "v01.11.0,v1.10.0,v1.9.3,v1.2.0-rc.1,v1.2.0,latest"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParseSemverTagsIgnore(t *testing.T) {
+	names := []string{"v1.2.0", "v1.3.0", "v2.0.0", "v1.2.5"}
+	tests := []struct {
+		ignore  string
+		want    string
+		wantErr bool
+	}{
+		{ignore: `^v2\.`, want: "v1.3.0,v1.2.5,v1.2.0"},
+		{ignore: `\.5$|^v1\.3`, want: "v2.0.0,v1.2.0"},
+		{ignore: `.*`, want: ""},
+		{ignore: `(`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ignore, func(t *testing.T) {
+			tags, err := parseSemverTags(names, Options{TagIgnore: tt.ignore})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := rawNames(tags); !tt.wantErr && got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSemverTagsContains(t *testing.T) {
+	editions := []string{"v1.2.3-prod", "v1.2.4-staging", "v1.2.2-PROD", "v1.1.0"}
+	platforms := []string{"v1.2.3_linux_amd64", "v1.2.4_darwin_arm64", "v1.2.2_LINUX_amd64", "v1.1.0"}
+	tests := []struct {
+		name  string
+		names []string
+		opts  Options
+		want  string
+	}{
+		{"prod", editions, Options{TagContains: "prod", Edition: "prod"}, "v1.2.3-prod"},
+		{"staging", editions, Options{TagContains: "staging", Edition: "staging"}, "v1.2.4-staging"},
+		{"suffix", platforms, Options{TagContains: "linux", KeepSuffix: true}, "v1.2.3_linux_amd64"},
+		{"ignore case", platforms, Options{TagContains: "linux", TagContainsIgnoreCase: true, KeepSuffix: true}, "v1.2.3_linux_amd64,v1.2.2_LINUX_amd64"},
+		{"no match", editions, Options{TagContains: "qa", Edition: "qa"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := parseSemverTags(tt.names, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagContainsNextVersion(t *testing.T) {
+	tags := writeFile(t, "tags.txt", "v1.2.3-prod\nv1.2.4-staging\n")
+	got, err := runArgs(t, "--tags-file", tags, "--tag-contains", "prod", "--edition", "prod", "--major", "1", "--minor", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4-prod" {
+		t.Errorf("got %q, want v1.2.4-prod", got)
+	}
+
+	platforms := writeFile(t, "platforms.txt", "v1.2.3_linux_amd64\nv1.2.5_darwin_arm64\n")
+	got, err = runArgs(t, "--tags-file", platforms, "--tag-contains", "_linux", "--keep-suffix", "--major", "1", "--minor", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.2.4_linux_amd64" {
+		t.Errorf("got %q, want v1.2.4_linux_amd64", got)
+	}
+
+	_, err = runArgs(t, "--tags-file", tags, "--tag-contains", "prod", "--major", "1", "--minor", "2")
+	if err == nil || err.Error() != "--tag-contains requires --edition or --keep-suffix" {
+		t.Errorf("error = %v, want --edition or --keep-suffix required", err)
+	}
+}
+
+func TestTagNamespace(t *testing.T) {
+	dir := newRepo(t, "team-a/v1.2.3", "team-b/v1.5.0", "v2.0.0", "team-a/v1.2.4")
+	tests := []struct {
+		namespace string
+		minor     string
+		want      string
+	}{
+		{"team-a", "2", "v1.2.5"},
+		{"team-a/", "2", "v1.2.5"},
+		{"team-b", "5", "v1.5.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			got, err := runArgs(t, "--path", dir, "--tag-namespace", tt.namespace, "--major", "1", "--minor", tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEpochAware(t *testing.T) {
+	names := []string{"v9.0.0", "1!v2.0.0", "1!v1.5.0", "2!v0.1.0"}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"epoch aware", Options{EpochAware: true}, "2!v0.1.0,1!v2.0.0,1!v1.5.0,v9.0.0"},
+		{"epochs ignored", Options{}, "v9.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := parseSemverTags(names, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			for _, tag := range tags {
+				if tag.String() != tag.Raw {
+					t.Errorf("%s renders as %s", tag.Raw, tag)
+				}
+			}
+		})
+	}
+
+	dir := newRepo(t, "v9.0.0", "1!v2.0.0")
+	got, err := runArgs(t, "--path", dir, "--epoch-aware", "--major", "2", "--minor", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "1!v2.1.0" {
+		t.Errorf("got %q, want 1!v2.1.0", got)
+	}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		components   int
+		tags         []string
+		major, minor string
+		want         string
+	}{
+		{2, []string{"v1.2", "v1.3", "v1.2.9"}, "1", "3", "v1.4"},
+		{2, []string{"v1.2"}, "2", "0", "v2.0"},
+		{3, []string{"v1.2.3", "v1.2.3.4"}, "1", "2", "v1.2.4"},
+		{4, []string{"v1.2.3.4", "v1.2.3"}, "1", "2", "v1.2.3.5"},
+		{4, []string{"v1.2.3.4"}, "1", "3", "v1.3.0.0"},
+		{4, nil, "0", "1", "v0.1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.tags, ",")+"/"+tt.want, func(t *testing.T) {
+			dir := newRepo(t, tt.tags...)
+			if len(tt.tags) == 0 {
+				commit(t, dir, "initial")
+			}
+			got, err := runArgs(t, "--path", dir, "--components", strconv.Itoa(tt.components), "--major", tt.major, "--minor", tt.minor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentsValidation(t *testing.T) {
+	for _, components := range []string{"1", "5"} {
+		err := validateOptions(parseArgs(t, "--path", ".", "--major", "1", "--minor", "0", "--components", components))
+		if err == nil || !strings.Contains(err.Error(), "must be 2, 3 or 4") {
+			t.Errorf("--components %s: error = %v", components, err)
+		}
+	}
+}
+
+func TestKeepSuffix(t *testing.T) {
+	names := []string{"v1.2.3_linux_amd64", "v1.2.4_darwin_arm64", "v1.2.1", "v1.2.2-rc.1_linux_amd64"}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"kept", Options{KeepSuffix: true, Prereleases: true}, "v1.2.4_darwin_arm64,v1.2.3_linux_amd64,v1.2.2-rc.1_linux_amd64,v1.2.1"},
+		{"not matched by default", Options{}, "v1.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := parseSemverTags(names, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			for _, tag := range tags {
+				if tag.String() != tag.Raw {
+					t.Errorf("%s round-trips as %s", tag.Raw, tag)
+				}
+			}
+		})
+	}
+
+	// Versions differing only by suffix compare equal
+	tags, _ := parseSemverTags([]string{"v1.2.3_linux_amd64", "v1.2.3_darwin_arm64"}, Options{KeepSuffix: true})
+	if Compare(tags[0], tags[1]) != 0 {
+		t.Errorf("suffix affects ordering: %s vs %s", tags[0].Raw, tags[1].Raw)
+	}
+}
+
+func TestStripPrefix(t *testing.T) {
+	names := []string{"v1.2.3", "release-1.3.0", "rel_1.2.9", "other-2.0.0"}
+	tests := []struct {
+		strip []string
+		want  string
+	}{
+		{nil, "v1.2.3"},
+		{[]string{"release-"}, "release-1.3.0,v1.2.3"},
+		{[]string{"release-", "rel_"}, "release-1.3.0,rel_1.2.9,v1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.strip, ","), func(t *testing.T) {
+			tags, err := parseSemverTags(names, Options{StripPrefix: tt.strip})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rawNames(tags); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	dir := newRepo(t, "v1.2.3", "release-1.3.0")
+	got, err := runArgs(t, "--path", dir, "--strip-prefix", "release-", "--major", "1", "--minor", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.3.1" {
+		t.Errorf("got %q, want v1.3.1", got)
+	}
+}
+
+func TestStartingPoint(t *testing.T) {
+	if got := Zero(); got != (SemVer{}) || got.String() != "v0.0.0" {
+		t.Errorf("Zero() = %+v", got)
+	}
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "v0.0.0"},
+		{Options{Prefix: "api-v"}, "api-v0.0.0"},
+		{Options{Components: 2}, "v0.0"},
+		{Options{Edition: "ce"}, "v0.0.0-ce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			tags, err := parseSemverTags([]string{"docs", ""}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tags) != 1 || tags[0].Raw != "" || tags[0].String() != tt.want {
+				t.Errorf("got %+v, want the %s starting point", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarnDuplicateTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		opts  Options
+		want  []string
+	}{
+		{"strip prefix", []string{"v1.2.3", "release-1.2.3", "v1.2.4"}, Options{StripPrefix: []string{"release-"}}, []string{"v1.2.3", "release-1.2.3"}},
+		{"prefix case", []string{"v1.2.3", "V1.2.3"}, Options{PrefixCase: "lower"}, []string{"v1.2.3", "V1.2.3"}},
+		{"distinct suffixes", []string{"v1.2.3_linux", "v1.2.3_darwin"}, Options{KeepSuffix: true}, nil},
+		{"distinct builds", []string{"v1.2.3_build1", "v1.2.3_build2"}, Options{UnderscoreBuild: true}, nil},
+		{"no duplicates", []string{"v1.2.3", "v1.2.4"}, Options{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+			if _, err := parseSemverTags(tt.names, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			warned := strings.Contains(logs.String(), "several tags parse to the same version")
+			if warned != (tt.want != nil) {
+				t.Fatalf("warned = %v: %s", warned, logs)
+			}
+			for _, name := range tt.want {
+				if !strings.Contains(logs.String(), name) {
+					t.Errorf("warning does not name %s: %s", name, logs)
+				}
+			}
+		})
+	}
+}
+
+func TestUnderscoreBuild(t *testing.T) {
+	tags, err := parseSemverTags([]string{"v1.2.3_build456", "v1.2.2", "v1.3.0-rc.1_build9"}, Options{UnderscoreBuild: true, Prereleases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rawNames(tags), "v1.3.0-rc.1_build9,v1.2.3_build456,v1.2.2"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if tags[1].Build != "build456" || tags[0].Build != "build9" || tags[0].Prerelease != "rc.1" {
+		t.Errorf("parsed %+v", tags)
+	}
+
+	dir := newRepo(t, "v1.2.3_build456")
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{[]string{"--underscore-build"}, "v1.2.4", false},
+		{[]string{"--underscore-build", "--keep-build"}, "v1.2.4_build456", false},
+		{[]string{"--keep-build"}, "", true},
+		{[]string{"--underscore-build", "--keep-suffix"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := runArgs(t, append([]string{"--path", dir, "--major", "1", "--minor", "2"}, tt.args...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsFile(t *testing.T) {
+	file := writeFile(t, "tags.txt", "v1.2.3\n\n  v1.3.0-rc.1  \ndocs\r\nv1.2.4\n")
+	names, err := readTagsFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(names, ","), "v1.2.3,v1.3.0-rc.1,docs,v1.2.4"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if _, err := readTagsFile(file + ".missing"); err == nil || !strings.Contains(err.Error(), "failed to read --tags-file") {
+		t.Errorf("error = %v, want a read failure", err)
+	}
+
+	got, err := runArgs(t, "--tags-file", file, "--major", "1", "--minor", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "v1.3.0" {
+		t.Errorf("got %q, want v1.3.0", got)
+	}
+}
+
+func TestGitHubRepoRejectsGitFeatures(t *testing.T) {
+	for _, flag := range []string{"--require-clean", "--from-branch", "--verify-signature", "--branch-aware", "--patch-from-commits"} {
+		err := validateOptions(parseArgs(t, "--github-repo", "owner/name", "--major", "1", "--minor", "2", flag))
+		if err == nil || !strings.Contains(err.Error(), flag+" requires a local --path") {
+			t.Errorf("%s: error = %v", flag, err)
+		}
+	}
+}
+
+func TestPrereleasesOptIn(t *testing.T) {
+	dir := newRepo(t, "v1.2.3", "v1.3.0-rc.1", "v2.0.0-beta.1")
+	editions := newRepo(t, "v1.2.3", "v1.2.4-ce", "v1.3.0-prod")
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"ignored by default", []string{"--path", dir, "--major", "1", "--minor", "2"}, "v1.2.4", ""},
+		{"patch of the latest", []string{"--path", dir, "--major", "1"}, "v1.2.4", ""},
+		{"read with --prereleases", []string{"--path", dir, "--major", "2", "--minor", "0", "--prereleases"}, "v2.0.0", ""},
+		{"latest with --prereleases", []string{"--path", dir, "--major", "1", "--minor", "2", "--prereleases"}, "", "input major (1) cannot be less than the latest major version (2)"},
+		{"edition suffixes", []string{"--path", editions, "--major", "1", "--minor", "2"}, "v1.2.4", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runArgs(t, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
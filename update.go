@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// updateFile replaces the version in the first match of pattern inside the
+// file with version. If the pattern has a capture group only the first group
+// is replaced, otherwise the whole match is. The file is rewritten atomically.
+func updateFile(path, pattern, version string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid --update-pattern: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	loc := re.FindSubmatchIndex(content)
+	if loc == nil {
+		return fmt.Errorf("no match for %q found in %s", pattern, path)
+	}
+	start, end := loc[0], loc[1]
+	if len(loc) > 2 && loc[2] >= 0 {
+		start, end = loc[2], loc[3]
+	}
+
+	updated := make([]byte, 0, len(content)+len(version))
+	updated = append(updated, content[:start]...)
+	updated = append(updated, version...)
+	updated = append(updated, content[end:]...)
+
+	return writeFileAtomic(path, updated)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestUpdateFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{"whole match", "version = v1.2.3\n", `v\d+\.\d+\.\d+`, "version = v1.3.0\n", false},
+		{"first group", `"version": "v1.2.3",`, `"version": "([^"]+)"`, `"version": "v1.3.0",`, false},
+		{"first match only", "v1.0.0 v1.0.0", `v1\.0\.0`, "v1.3.0 v1.0.0", false},
+		{"no match", "nothing here", `v\d+`, "nothing here", true},
+		{"invalid pattern", "v1.2.3", `(`, "v1.2.3", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "VERSION", tt.content)
+			err := updateFile(path, tt.pattern, "v1.3.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateFileRun(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	path := writeFile(t, "version.go", "const Version = \"v1.2.3\"\n")
+	got, err := runArgs(t, "--path", dir, "--major", "1", "--minor", "2", "--update-file", path, "--update-pattern", `Version = "([^"]+)"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if got != "v1.2.4" || string(content) != "const Version = \"v1.2.4\"\n" {
+		t.Errorf("printed %q, file %q", got, content)
+	}
+}
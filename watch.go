@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// runWatch polls the tags of the repository and prints the next version every
+// time the tag set changes, until interrupted
+func runWatch(opts Options, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(opts.WatchInterval)
+	defer ticker.Stop()
+
+	seen := ""
+	first := true
+	for {
+		names, err := listGitTags(opts.Path, opts)
+		if err != nil {
+			return err
+		}
+		if snapshot := strings.Join(names, "\n"); first || snapshot != seen {
+			seen, first = snapshot, false
+			if latestTag, nextVersion, err := computeNextVersion(opts.Path, opts); err != nil {
+				// Keep watching, a later tag may fix the input
+				slog.Error(err.Error())
+			} else {
+				fmt.Fprintln(out, formatVersion(latestTag, nextVersion, opts))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while another goroutine writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor polls until the output is want or the deadline passes
+func waitFor(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %q, want %q", out.String(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := newRepo(t, "v1.2.3")
+	opts := parseArgs(t, "--path", dir, "--watch", "--watch-interval", "10ms", "--major", "1", "--minor", "2")
+	var out syncBuffer
+	done := make(chan error)
+	go func() { done <- runWatch(opts, &out) }()
+
+	waitFor(t, &out, "v1.2.4\n")
+	commit(t, dir, "fix")
+	runGit(t, dir, "tag", "v1.2.4")
+	waitFor(t, &out, "v1.2.4\nv1.2.5\n")
+
+	// An interrupt stops watching cleanly
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("--watch did not stop on interrupt")
+	}
+	if strings.Count(out.String(), "\n") != 2 {
+		t.Errorf("printed again without a tag change: %q", out.String())
+	}
+}